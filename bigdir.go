@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// maxBigDirCache bounds how many directory counts are remembered.
+const maxBigDirCache = 4096
+
+// bigDirCount caches whether a directory exceeded the threshold, valid while
+// the directory's modification time is unchanged.
+type bigDirCount struct {
+	modTime   time.Time
+	threshold int
+	big       bool
+}
+
+// IsBigDir reports whether item is a directory with more entries than the
+// big_dir_entries setting. Counts stop at the threshold and are cached.
+// Remote directories are not counted, as SFTP can only list one whole.
+func (n *Navigator) IsBigDir(item FileItem) bool {
+	threshold := n.config.BigDirEntries
+	if threshold <= 0 || !item.IsDir || item.IsParent || n.isRemote() {
+		return false
+	}
+	item = n.withMetadata(item)
+	if cached, ok := n.bigDirs[item.Path]; ok && cached.modTime.Equal(item.ModTime) && cached.threshold == threshold {
+		return cached.big
+	}
+
+	big := hasMoreEntries(item.Path, threshold)
+	if n.bigDirs == nil || len(n.bigDirs) >= maxBigDirCache {
+		n.bigDirs = make(map[string]bigDirCount)
+	}
+	n.bigDirs[item.Path] = bigDirCount{modTime: item.ModTime, threshold: threshold, big: big}
+	return big
+}
+
+// hasMoreEntries reports whether dir holds more than limit entries, reading no
+// more names than needed to tell. Unreadable directories are not big.
+func hasMoreEntries(dir string, limit int) bool {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	names, _ := f.Readdirnames(limit + 1)
+	return len(names) > limit
+}
+
+// ShouldWarnBeforeEntering reports whether opening the selected item descends
+// into a big directory, so the caller can ask first.
+func (n *Navigator) ShouldWarnBeforeEntering() bool {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil {
+		return false
+	}
+	if flat, _ := n.IsFlatView(); flat {
+		return false
+	}
+	return n.IsBigDir(*selectedItem)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsBigDir(t *testing.T) {
+	tempDir := t.TempDir()
+	big := filepath.Join(tempDir, "big")
+	os.Mkdir(big, 0755)
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(big, fmt.Sprintf("f%d", i)), nil, 0644)
+	}
+	os.Mkdir(filepath.Join(tempDir, "small"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "small", "only"), nil, 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	item := func(name string) FileItem {
+		selectByName(t, nav, name)
+		return *nav.GetSelectedItem()
+	}
+
+	if nav.IsBigDir(item("big")) {
+		t.Error("Expected no directory to be big with the threshold off")
+	}
+
+	cfg := nav.GetConfig()
+	cfg.BigDirEntries = 4
+	nav.SetConfig(cfg)
+	if !nav.IsBigDir(item("big")) {
+		t.Error("Expected 5 entries to exceed a threshold of 4")
+	}
+	if nav.IsBigDir(item("small")) || nav.IsBigDir(item("../")) {
+		t.Error("Expected small and ../ not to be big")
+	}
+
+	// Exactly the threshold is not over it
+	cfg.BigDirEntries = 5
+	nav.SetConfig(cfg)
+	if nav.IsBigDir(item("big")) {
+		t.Error("Expected 5 entries not to exceed a threshold of 5")
+	}
+}
+
+func TestIsBigDirCached(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "dir")
+	os.Mkdir(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "a"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "b"), nil, 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.BigDirEntries = 1
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	selectByName(t, nav, "dir")
+	item := *nav.GetSelectedItem()
+
+	if !nav.IsBigDir(item) {
+		t.Fatal("Expected dir to be big")
+	}
+	// Without a new modification time the cached answer is reused
+	os.Remove(filepath.Join(dir, "a"))
+	os.Remove(filepath.Join(dir, "b"))
+	if !nav.IsBigDir(item) {
+		t.Error("Expected the cached count to be reused for an unchanged item")
+	}
+	nav.Refresh()
+	selectByName(t, nav, "dir")
+	if nav.IsBigDir(*nav.GetSelectedItem()) {
+		t.Error("Expected a new modification time to recount")
+	}
+}
+
+func TestShouldWarnBeforeEntering(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "dir")
+	os.Mkdir(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "a"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "b"), nil, 0644)
+	os.WriteFile(filepath.Join(tempDir, "file.txt"), nil, 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.BigDirEntries = 1
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	selectByName(t, nav, "dir")
+	if !nav.ShouldWarnBeforeEntering() {
+		t.Error("Expected a warning before entering a big directory")
+	}
+	selectByName(t, nav, "file.txt")
+	if nav.ShouldWarnBeforeEntering() {
+		t.Error("Expected no warning for a file")
+	}
+}
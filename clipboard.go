@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// writeClipboard copies text to the system clipboard using the platform's
+// clipboard tool.
+func writeClipboard(text string) error {
+	command, args, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(command, args...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// clipboardCommand returns the command that reads stdin into the clipboard.
+func clipboardCommand() (string, []string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return "pbcopy", nil, nil
+	case "windows":
+		return "clip", nil, nil
+	}
+
+	// Linux and other Unix-like systems: prefer Wayland, then X11 tools
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return candidate[0], candidate[1:], nil
+		}
+	}
+	return "", nil, errors.New("no clipboard tool found (install wl-copy, xclip or xsel)")
+}
+
+// CopyRelativePath copies the selected item's path relative to base to the clipboard.
+func (n *Navigator) CopyRelativePath(base string) error {
+	selectedItem := n.requireSelection()
+	if selectedItem == nil {
+		return nil
+	}
+	rel, err := relativeTo(base, selectedItem.Path)
+	if err != nil {
+		return err
+	}
+	return n.clipboard(rel)
+}
+
+// CopyCurrentPath copies the current directory's path to the clipboard.
+func (n *Navigator) CopyCurrentPath() error {
+	return n.clipboard(n.currentPath)
+}
+
+// CopyMarkedPaths copies the absolute paths of the marked items to the
+// clipboard, joined by the copy_separator setting, and returns how many
+// were copied.
+func (n *Navigator) CopyMarkedPaths() (int, error) {
+	markedItems := n.GetMarkedItems()
+	if len(markedItems) == 0 {
+		n.SetStatusMessage("Nothing marked")
+		return 0, nil
+	}
+	paths := make([]string, len(markedItems))
+	for i, item := range markedItems {
+		paths[i] = item.Path
+	}
+	return len(paths), n.clipboard(strings.Join(paths, n.config.CopySeparator))
+}
+
+// parseCopySeparator reads "newline" or "null" into the separator it names.
+// Null-separated paths can be pasted into xargs -0 even when names contain
+// newlines.
+func parseCopySeparator(value string, dst *string) error {
+	switch value {
+	case "newline":
+		*dst = "\n"
+	case "null":
+		*dst = "\x00"
+	default:
+		return fmt.Errorf("invalid copy separator %q: expected newline or null", value)
+	}
+	return nil
+}
+
+// resolveBase turns the answer to the "relative to" prompt into a base path:
+// "s" (or nothing) for the start directory, "r" for the project root, or a path.
+func (n *Navigator) resolveBase(choice string) (string, error) {
+	switch choice = strings.TrimSpace(choice); choice {
+	case "", "s":
+		return n.startPath, nil
+	case "r":
+		return findProjectRoot(n.currentPath)
+	}
+	if !filepath.IsAbs(choice) {
+		choice = filepath.Join(n.currentPath, choice)
+	}
+	return filepath.Clean(choice), nil
+}
+
+// relativeTo returns target relative to base, failing clearly when they are on
+// different volumes (such as separate Windows drives).
+func relativeTo(base, target string) (string, error) {
+	if baseVol, targetVol := filepath.VolumeName(base), filepath.VolumeName(target); !strings.EqualFold(baseVol, targetVol) {
+		return "", fmt.Errorf("cannot make %s relative to %s: they are on different volumes", target, base)
+	}
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return "", fmt.Errorf("cannot make %s relative to %s: %w", target, base, err)
+	}
+	return rel, nil
+}
+
+// findProjectRoot returns the nearest directory at or above path that contains
+// a .git entry.
+func findProjectRoot(path string) (string, error) {
+	dir := path
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
+		}
+		if isRoot(dir) {
+			return "", fmt.Errorf("no project root (.git) found above %s", path)
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRelativeTo(t *testing.T) {
+	root := filepath.FromSlash("/home/user/project")
+	target := filepath.Join(root, "src", "main.go")
+
+	tests := []struct {
+		base string
+		want string
+	}{
+		{root, filepath.Join("src", "main.go")},
+		{filepath.Join(root, "src"), "main.go"},
+		{filepath.Join(root, "docs"), filepath.Join("..", "src", "main.go")},
+		{target, "."},
+	}
+	for _, tt := range tests {
+		got, err := relativeTo(tt.base, target)
+		if err != nil || got != tt.want {
+			t.Errorf("relativeTo(%q, %q) = %q, %v; want %q", tt.base, target, got, err, tt.want)
+		}
+	}
+
+	// A relative base cannot be related to an absolute target
+	if _, err := relativeTo("relative", target); err == nil {
+		t.Error("relativeTo expected an error for a relative base")
+	}
+}
+
+func TestCopyRelativePath(t *testing.T) {
+	projectDir := t.TempDir()
+	os.Mkdir(filepath.Join(projectDir, ".git"), 0755)
+	os.MkdirAll(filepath.Join(projectDir, "src", "pkg"), 0755)
+	os.WriteFile(filepath.Join(projectDir, "src", "pkg", "main.go"), []byte("package main"), 0644)
+
+	nav, _ := NewNavigator(filepath.Join(projectDir, "src"))
+	var copied string
+	nav.clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	nav.GoToPath(filepath.Join("pkg", "main.go"))
+
+	tests := []struct {
+		choice string
+		want   string
+	}{
+		{"s", filepath.Join("pkg", "main.go")},               // Start directory (src)
+		{"", filepath.Join("pkg", "main.go")},                // Start directory is the default
+		{"r", filepath.Join("src", "pkg", "main.go")},        // Project root
+		{"..", filepath.Join("pkg", "main.go")},              // Relative to the current directory
+		{projectDir, filepath.Join("src", "pkg", "main.go")}, // Absolute path
+		{filepath.Join(projectDir, "docs"), filepath.Join("..", "src", "pkg", "main.go")},
+	}
+	for _, tt := range tests {
+		base, err := nav.resolveBase(tt.choice)
+		if err != nil {
+			t.Errorf("resolveBase(%q) failed: %v", tt.choice, err)
+			continue
+		}
+		if err := nav.CopyRelativePath(base); err != nil {
+			t.Errorf("CopyRelativePath(%q) failed: %v", base, err)
+			continue
+		}
+		if copied != tt.want {
+			t.Errorf("Relative to %q copied %q, want %q", tt.choice, copied, tt.want)
+		}
+	}
+}
+
+func TestCopyCurrentPath(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	var copied string
+	nav.clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+
+	// The selection does not matter, only the directory being shown
+	selectByName(t, nav, "file1.txt")
+	if err := nav.CopyCurrentPath(); err != nil {
+		t.Fatalf("CopyCurrentPath failed: %v", err)
+	}
+	if copied != tempDir {
+		t.Errorf("Expected %q on the clipboard, got %q", tempDir, copied)
+	}
+
+	selectByName(t, nav, "dir1")
+	nav.OpenSelected()
+	nav.CopyCurrentPath()
+	if want := filepath.Join(tempDir, "dir1"); copied != want {
+		t.Errorf("Expected %q on the clipboard, got %q", want, copied)
+	}
+}
+
+func TestCopyMarkedPaths(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	var copied string
+	nav.clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+
+	if count, _ := nav.CopyMarkedPaths(); count != 0 || copied != "" {
+		t.Errorf("Expected nothing copied without marks, got %d paths", count)
+	}
+
+	for _, name := range []string{"dir1", "file1.txt", ".hidden_file"} {
+		selectByName(t, nav, name)
+		nav.ToggleMark()
+	}
+	// Paths come in listing order: directories first, then files
+	want := []string{
+		filepath.Join(tempDir, "dir1"),
+		filepath.Join(tempDir, ".hidden_file"),
+		filepath.Join(tempDir, "file1.txt"),
+	}
+	count, err := nav.CopyMarkedPaths()
+	if err != nil {
+		t.Fatalf("CopyMarkedPaths failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 paths copied, got %d", count)
+	}
+	if joined := strings.Join(want, "\n"); copied != joined {
+		t.Errorf("Expected %q on the clipboard, got %q", joined, copied)
+	}
+
+	cfg := nav.GetConfig()
+	if err := cfg.set("copy_separator", "null"); err != nil {
+		t.Fatalf("copy_separator = null failed: %v", err)
+	}
+	nav.SetConfig(cfg)
+	nav.CopyMarkedPaths()
+	if joined := strings.Join(want, "\x00"); copied != joined {
+		t.Errorf("Expected %q on the clipboard, got %q", joined, copied)
+	}
+
+	if err := cfg.set("copy_separator", "tab"); err == nil {
+		t.Error("Expected an error for an unknown copy separator")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CollisionPolicy selects what a move does when its destination already has
+// an entry of the same name.
+type CollisionPolicy int
+
+const (
+	CollisionRefuse CollisionPolicy = iota
+	CollisionSkip
+	CollisionOverwrite
+	CollisionRename
+	CollisionAsk
+)
+
+// collisionPolicyNames maps collision policies to their config names.
+var collisionPolicyNames = []string{
+	CollisionRefuse:    "refuse",
+	CollisionSkip:      "skip",
+	CollisionOverwrite: "overwrite",
+	CollisionRename:    "rename",
+	CollisionAsk:       "ask",
+}
+
+// String returns the name of the collision policy.
+func (p CollisionPolicy) String() string {
+	if int(p) < len(collisionPolicyNames) {
+		return collisionPolicyNames[p]
+	}
+	return "unknown"
+}
+
+// parseCollisionPolicy parses a collision policy name into dst.
+func parseCollisionPolicy(value string, dst *CollisionPolicy) error {
+	for policy, name := range collisionPolicyNames {
+		if name == value {
+			*dst = CollisionPolicy(policy)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid collision policy %q: expected one of %s", value, strings.Join(collisionPolicyNames, ", "))
+}
+
+// parseCollisionAnswer parses the answer to a collision prompt: s, o or r to
+// skip, overwrite or rename, in capitals to apply it to every remaining
+// collision. An empty answer skips.
+func parseCollisionAnswer(text string) (policy CollisionPolicy, all bool, err error) {
+	answer := strings.TrimSpace(text)
+	all = answer != "" && answer == strings.ToUpper(answer)
+	switch strings.ToLower(answer) {
+	case "", "s":
+		return CollisionSkip, all, nil
+	case "o":
+		return CollisionOverwrite, all, nil
+	case "r":
+		return CollisionRename, all, nil
+	}
+	return CollisionRefuse, false, fmt.Errorf("invalid answer %q: expected s, o or r", answer)
+}
+
+// MoveCollisions returns the names of the items to move that already exist
+// in dest, in the order they would be moved.
+func (n *Navigator) MoveCollisions(dest string) ([]string, error) {
+	destDir, err := n.resolveDestDir(dest)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range n.moveTargets() {
+		name := filepath.Base(item.Path)
+		newPath := filepath.Join(destDir, name)
+		if newPath == item.Path {
+			continue
+		}
+		if _, err := os.Lstat(newPath); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// collisionPolicy returns what to do about the existing entry name: the
+// choice made for it, or else the on_collision setting. Collisions left to
+// ask about with no answer are refused.
+func (n *Navigator) collisionPolicy(name string, choices map[string]CollisionPolicy) CollisionPolicy {
+	if policy, ok := choices[name]; ok {
+		return policy
+	}
+	if n.config.OnCollision == CollisionAsk {
+		return CollisionRefuse
+	}
+	return n.config.OnCollision
+}
+
+// freeName returns a path in dir for name that nothing exists at yet, adding
+// " (1)", " (2)" and so on before the extension.
+func freeName(dir, name string) string {
+	stem, ext := splitStem(name)
+	for i := 1; ; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path
+		}
+	}
+}
+
+// moveReplacing moves src to dst in place of the entry already there.
+func (n *Navigator) moveReplacing(src, dst string) error {
+	return replaceEntry(src, dst, func() error {
+		return movePath(src, dst, n.config.MaxDepth, n.config.PreserveAttributes, n.config.OneFileSystem)
+	})
+}
+
+// replaceEntry runs create to put src at dst in place of the entry already
+// there. The old entry is set aside until create succeeds, so a failure
+// leaves it as it was; create cleans up after itself. If the old entry cannot
+// be put back, the error says where it was left.
+func replaceEntry(src, dst string, create func() error) error {
+	if strings.HasPrefix(src, dst+string(filepath.Separator)) {
+		return fmt.Errorf("cannot overwrite %s, which contains it", dst)
+	}
+	aside := freeName(filepath.Dir(dst), "."+filepath.Base(dst)+".old")
+	if err := renameFile(dst, aside); err != nil {
+		return err
+	}
+	if err := create(); err != nil {
+		if restoreErr := renameFile(aside, dst); restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("cannot put %s back, it was left at %s: %w", filepath.Base(dst), aside, restoreErr))
+		}
+		return err
+	}
+	return os.RemoveAll(aside)
+}
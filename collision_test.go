@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveCollisionPolicies(t *testing.T) {
+	tests := []struct {
+		policy   CollisionPolicy
+		wantErr  bool
+		existing string // What dest/notes.txt holds afterwards
+		renamed  bool   // Whether dest/notes (1).txt holds the moved file
+		moved    bool   // Whether the source is gone
+	}{
+		{CollisionRefuse, true, "existing", false, false},
+		{CollisionSkip, false, "existing", false, false},
+		{CollisionOverwrite, false, "moved", false, true},
+		{CollisionRename, false, "existing", true, true},
+		{CollisionAsk, true, "existing", false, false}, // Nothing was answered
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			dir := t.TempDir()
+			os.Mkdir(filepath.Join(dir, "dest"), 0755)
+			os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("moved"), 0644)
+			os.WriteFile(filepath.Join(dir, "dest", "notes.txt"), []byte("existing"), 0644)
+
+			nav, _ := NewNavigator(dir)
+			cfg := nav.GetConfig()
+			cfg.OnCollision = tt.policy
+			nav.SetConfig(cfg)
+			nav.ScanDirectory()
+			selectByName(t, nav, "notes.txt")
+
+			if names, err := nav.MoveCollisions("dest"); err != nil || len(names) != 1 || names[0] != "notes.txt" {
+				t.Fatalf("Expected notes.txt to collide, got %v (err %v)", names, err)
+			}
+			if err := nav.MoveItems("dest", nil); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if data, _ := os.ReadFile(filepath.Join(dir, "dest", "notes.txt")); string(data) != tt.existing {
+				t.Errorf("Expected dest/notes.txt to hold %q, got %q", tt.existing, data)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "dest", "notes (1).txt"))
+			if renamed := err == nil && string(data) == "moved"; renamed != tt.renamed {
+				t.Errorf("Expected a renamed copy %v, got %v", tt.renamed, renamed)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "notes.txt")); os.IsNotExist(err) != tt.moved {
+				t.Errorf("Expected the source moved %v, got %v", tt.moved, os.IsNotExist(err))
+			}
+			if entries, _ := os.ReadDir(filepath.Join(dir, "dest")); tt.policy == CollisionOverwrite && len(entries) != 1 {
+				t.Errorf("Expected the replaced entry to be removed, got %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestMoveCollisionChoices(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "dest"), 0755)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("moved"), 0644)
+		os.WriteFile(filepath.Join(dir, "dest", name), []byte("existing"), 0644)
+	}
+
+	nav, _ := NewNavigator(dir)
+	nav.ScanDirectory()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		selectByName(t, nav, name)
+		nav.ToggleMark()
+	}
+
+	// A choice per name wins over on_collision
+	choices := map[string]CollisionPolicy{"a.txt": CollisionOverwrite, "b.txt": CollisionSkip}
+	if err := nav.MoveItems("dest", choices); err != nil {
+		t.Fatalf("MoveItems failed: %v", err)
+	}
+	for name, want := range map[string]string{"a.txt": "moved", "b.txt": "existing"} {
+		if data, _ := os.ReadFile(filepath.Join(dir, "dest", name)); string(data) != want {
+			t.Errorf("Expected dest/%s to hold %q, got %q", name, want, data)
+		}
+	}
+}
+
+func TestReplaceEntryNamesUnrestoredEntry(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "notes.txt")
+	os.WriteFile(dst, []byte("existing"), 0644)
+
+	// Setting the old entry aside works, putting it back does not
+	renameFile = func(oldpath, newpath string) error {
+		if newpath == dst {
+			return os.ErrPermission
+		}
+		return os.Rename(oldpath, newpath)
+	}
+	defer func() { renameFile = os.Rename }()
+
+	aside := freeName(dir, ".notes.txt.old")
+	createErr := errors.New("simulated failure")
+	err := replaceEntry(filepath.Join(dir, "new.txt"), dst, func() error { return createErr })
+	if !errors.Is(err, createErr) || !errors.Is(err, os.ErrPermission) || !strings.Contains(err.Error(), aside) {
+		t.Errorf("Expected both failures and %s in the error, got %v", aside, err)
+	}
+	if data, _ := os.ReadFile(aside); string(data) != "existing" {
+		t.Errorf("Expected the old entry kept at %s, got %q", aside, data)
+	}
+}
+
+func TestParseCollisionAnswer(t *testing.T) {
+	tests := []struct {
+		text   string
+		policy CollisionPolicy
+		all    bool
+	}{
+		{"", CollisionSkip, false},
+		{"s", CollisionSkip, false},
+		{"o", CollisionOverwrite, false},
+		{"R", CollisionRename, true},
+		{" O ", CollisionOverwrite, true},
+	}
+	for _, tt := range tests {
+		policy, all, err := parseCollisionAnswer(tt.text)
+		if err != nil || policy != tt.policy || all != tt.all {
+			t.Errorf("parseCollisionAnswer(%q) = %v, %v, %v; want %v, %v", tt.text, policy, all, err, tt.policy, tt.all)
+		}
+	}
+	if _, _, err := parseCollisionAnswer("x"); err == nil {
+		t.Error("Expected an unknown answer to be rejected")
+	}
+}
+
+func TestFreeName(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes (1).txt"), nil, 0644)
+	if got := freeName(dir, "notes.txt"); got != filepath.Join(dir, "notes (2).txt") {
+		t.Errorf("Expected notes (2).txt, got %s", got)
+	}
+	if got := freeName(dir, ".bashrc"); got != filepath.Join(dir, ".bashrc (1)") {
+		t.Errorf("Expected .bashrc (1), got %s", got)
+	}
+}
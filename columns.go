@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Column identifies a metadata column shown before the item name.
+type Column int
+
+const (
+	ColumnPerms Column = iota
+	ColumnSize
+	ColumnDate
+)
+
+// activeColumns returns the enabled metadata columns in display order.
+func activeColumns(cfg Config) []Column {
+	var columns []Column
+	if cfg.ShowPerms {
+		columns = append(columns, ColumnPerms)
+	}
+	if cfg.ShowSize {
+		columns = append(columns, ColumnSize)
+	}
+	if cfg.ShowDate {
+		columns = append(columns, ColumnDate)
+	}
+	return columns
+}
+
+// Built-in date column formats; any other value is a Go time layout.
+const (
+	DateFormatISO      = "iso"
+	DateFormatRelative = "relative"
+)
+
+// isoDateLayout is the layout used by the "iso" date format.
+const isoDateLayout = "2006-01-02 15:04"
+
+// columnCell formats the value of a metadata column for item. Relative dates
+// are measured from now.
+func columnCell(item FileItem, column Column, cfg Config, now time.Time) string {
+	if item.IsParent {
+		return ""
+	}
+	switch column {
+	case ColumnPerms:
+		return item.Mode.String()
+	case ColumnSize:
+		if item.IsDir {
+			return "-"
+		}
+		return formatSize(displaySize(item, cfg.SizeOnDisk))
+	case ColumnDate:
+		return formatDate(item.ModTime, cfg.DateFormat, now)
+	}
+	return ""
+}
+
+// minCompactName is the narrowest a name is cut to before the compact view
+// gives up its details to show more of the name.
+const minCompactName = 6
+
+// compactDetails returns what the compact view shows after item's name: the
+// size and a relative date, or only the date for directories.
+func compactDetails(item FileItem, cfg Config, now time.Time) string {
+	if item.IsParent {
+		return ""
+	}
+	age := formatDate(item.ModTime, DateFormatRelative, now)
+	if item.IsDir {
+		return age
+	}
+	return formatSize(displaySize(item, cfg.SizeOnDisk)) + "  " + age
+}
+
+// compactRow fits name followed by details into width terminal columns. The
+// name is truncated to make room, and when that would leave fewer than
+// minCompactName columns the details are left out instead.
+func compactRow(name, details string, width int, ellipsis string) string {
+	if details == "" {
+		return truncateFilename(name, width, ellipsis)
+	}
+	if row := name + "  " + details; runewidth.StringWidth(row) <= width {
+		return row
+	}
+	available := width - runewidth.StringWidth(details) - 2
+	if available < minCompactName {
+		return truncateFilename(name, width, ellipsis)
+	}
+	return truncateFilename(name, available, ellipsis) + "  " + details
+}
+
+// ToggleCompactDetails switches between the plain listing and one showing the
+// size and age after each name.
+func (n *Navigator) ToggleCompactDetails() {
+	n.config.CompactDetails = !n.config.CompactDetails
+	n.loadMetadata()
+}
+
+// formatDate formats t with a built-in date format or a custom time layout.
+func formatDate(t time.Time, format string, now time.Time) string {
+	switch format {
+	case DateFormatISO, "":
+		return t.Format(isoDateLayout)
+	case DateFormatRelative:
+		return formatAge(now.Sub(t))
+	default:
+		return t.Format(format)
+	}
+}
+
+// formatAge formats a duration as a short age such as "5m ago" or "3d ago".
+func formatAge(age time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", age/time.Minute)
+	case age < day:
+		return fmt.Sprintf("%dh ago", age/time.Hour)
+	case age < 30*day:
+		return fmt.Sprintf("%dd ago", age/day)
+	case age < 365*day:
+		return fmt.Sprintf("%dmo ago", age/(30*day))
+	default:
+		return fmt.Sprintf("%dy ago", age/(365*day))
+	}
+}
+
+// validDateLayout reports whether layout contains at least one time element,
+// so that it does not format every date as the same literal text.
+func validDateLayout(layout string) bool {
+	reference := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	return reference.Format(layout) != layout
+}
+
+// columnRows builds the metadata cells for each item, plus a trailing empty cell
+// marking where the name column starts.
+func columnRows(items []FileItem, columns []Column, cfg Config, now time.Time) [][]string {
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		row := make([]string, 0, len(columns)+1)
+		for _, column := range columns {
+			row = append(row, columnCell(item, column, cfg, now))
+		}
+		rows[i] = append(row, "")
+	}
+	return rows
+}
+
+// displaySize returns the size to show for item: the space allocated on disk
+// when onDisk is set and known, otherwise the apparent size.
+func displaySize(item FileItem, onDisk bool) int64 {
+	if onDisk && item.DiskSize >= 0 {
+		return item.DiskSize
+	}
+	return item.Size
+}
+
+// formatSize formats a byte count in human-readable binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// layoutColumns computes the x-offset of each column from the widest cell in
+// the previous column plus padding. A separator, when set, sits in the middle
+// of the gap with padding on both sides.
+func layoutColumns(rows [][]string, padding int, separator string) []int {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	gap := padding
+	if separator != "" {
+		gap = padding + utf8.RuneCountInString(separator) + padding
+	}
+
+	offsets := make([]int, len(widths))
+	for i := 1; i < len(widths); i++ {
+		offsets[i] = offsets[i-1] + widths[i-1] + gap
+	}
+	return offsets
+}
+
+// columnSeparatorX returns where the separator before column i is drawn.
+func columnSeparatorX(offsets []int, i, padding int, separator string) int {
+	return offsets[i] - padding - utf8.RuneCountInString(separator)
+}
+
+// ViewPreset is a named set of metadata columns switched on together. Columns
+// are listed in display order.
+type ViewPreset struct {
+	Name    string
+	Columns []Column
+}
+
+// viewPresets lists the presets in the order the view key cycles through them.
+var viewPresets = []ViewPreset{
+	{Name: "names"},
+	{Name: "long", Columns: []Column{ColumnPerms, ColumnSize, ColumnDate}},
+}
+
+// apply enables exactly the preset's columns in cfg.
+func (p ViewPreset) apply(cfg *Config) {
+	cfg.ShowPerms, cfg.ShowSize, cfg.ShowDate = false, false, false
+	for _, column := range p.Columns {
+		switch column {
+		case ColumnPerms:
+			cfg.ShowPerms = true
+		case ColumnSize:
+			cfg.ShowSize = true
+		case ColumnDate:
+			cfg.ShowDate = true
+		}
+	}
+}
+
+// matches reports whether cfg shows exactly the preset's columns.
+func (p ViewPreset) matches(cfg Config) bool {
+	return slices.Equal(activeColumns(cfg), p.Columns)
+}
+
+// CycleViewPreset switches to the preset after the one currently shown and
+// returns its name. A custom column selection starts again from the first preset.
+func (n *Navigator) CycleViewPreset() string {
+	next := 0
+	for i, preset := range viewPresets {
+		if preset.matches(n.config) {
+			next = (i + 1) % len(viewPresets)
+			break
+		}
+	}
+	viewPresets[next].apply(&n.config)
+	n.loadMetadata()
+	return viewPresets[next].Name
+}
+
+// ToggleSizeOnDisk switches the size column between apparent and on-disk size.
+func (n *Navigator) ToggleSizeOnDisk() {
+	n.config.SizeOnDisk = !n.config.SizeOnDisk
+}
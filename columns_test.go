@@ -0,0 +1,238 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestLayoutColumns(t *testing.T) {
+	rows := [][]string{
+		{"-rw-r--r--", "12B", ""},
+		{"drwxr-xr-x", "-", ""},
+		{"-rwxr-xr-x", "1.5K", ""},
+	}
+
+	tests := []struct {
+		padding   int
+		separator string
+		want      []int
+	}{
+		{2, "", []int{0, 12, 18}},  // 10+2, then 12+4+2
+		{0, "", []int{0, 10, 14}},  // Cells touch without padding
+		{1, "|", []int{0, 13, 20}}, // 10+1+1+1, then 13+4+3
+		{2, " | ", []int{0, 17, 28}},
+	}
+	for _, tt := range tests {
+		got := layoutColumns(rows, tt.padding, tt.separator)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("layoutColumns(padding=%d, separator=%q) = %v, want %v", tt.padding, tt.separator, got, tt.want)
+		}
+	}
+
+	if got := layoutColumns(nil, 2, ""); got != nil {
+		t.Errorf("layoutColumns with no rows expected nil, got %v", got)
+	}
+}
+
+func TestColumnSeparatorX(t *testing.T) {
+	offsets := layoutColumns([][]string{{"abcd", "xy", ""}}, 1, "|")
+	// "abcd" ends at 4, then a space, the separator, and a space before column 1
+	if x := columnSeparatorX(offsets, 1, 1, "|"); x != 5 {
+		t.Errorf("Expected separator at x=5, got %d", x)
+	}
+}
+
+func TestColumnCells(t *testing.T) {
+	modTime := time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC)
+	file := FileItem{Name: "a.txt", Size: 2048, Mode: 0644, ModTime: modTime}
+	dir := FileItem{Name: "sub", IsDir: true, Mode: os.ModeDir | 0755, ModTime: modTime}
+	parent := FileItem{Name: "../", IsDir: true, IsParent: true}
+
+	cfg := DefaultConfig()
+	cfg.ShowPerms, cfg.ShowSize, cfg.ShowDate = true, true, true
+	rows := columnRows([]FileItem{file, dir, parent}, activeColumns(cfg), cfg, modTime)
+
+	want := [][]string{
+		{"-rw-r--r--", "2.0K", "2024-03-09 14:05", ""},
+		{"drwxr-xr-x", "-", "2024-03-09 14:05", ""},
+		{"", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("columnRows = %q, want %q", rows, want)
+	}
+
+	cfg.ShowPerms = false
+	if got := activeColumns(cfg); !reflect.DeepEqual(got, []Column{ColumnSize, ColumnDate}) {
+		t.Errorf("activeColumns without perms = %v", got)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0B",
+		1023:            "1023B",
+		1024:            "1.0K",
+		1536:            "1.5K",
+		5 * 1024 * 1024: "5.0M",
+		3 << 30:         "3.0G",
+	}
+	for size, want := range tests {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestViewPresetApply(t *testing.T) {
+	tests := []struct {
+		preset            string
+		perms, size, date bool
+	}{
+		{"names", false, false, false},
+		{"long", true, true, true},
+	}
+	for _, tt := range tests {
+		var preset ViewPreset
+		for _, p := range viewPresets {
+			if p.Name == tt.preset {
+				preset = p
+			}
+		}
+		cfg := Config{ShowPerms: true, ShowSize: false, ShowDate: true}
+		preset.apply(&cfg)
+		if cfg.ShowPerms != tt.perms || cfg.ShowSize != tt.size || cfg.ShowDate != tt.date {
+			t.Errorf("Preset %q expected perms=%v size=%v date=%v, got perms=%v size=%v date=%v",
+				tt.preset, tt.perms, tt.size, tt.date, cfg.ShowPerms, cfg.ShowSize, cfg.ShowDate)
+		}
+		if !preset.matches(cfg) {
+			t.Errorf("Preset %q expected to match the config it was applied to", tt.preset)
+		}
+	}
+}
+
+func TestCycleViewPreset(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+
+	if name := nav.CycleViewPreset(); name != "long" {
+		t.Errorf("Expected names view to switch to long, got %q", name)
+	}
+	if cols := activeColumns(nav.GetConfig()); len(cols) != 3 {
+		t.Errorf("Expected 3 columns in the long view, got %d", len(cols))
+	}
+	if name := nav.CycleViewPreset(); name != "names" {
+		t.Errorf("Expected long view to switch back to names, got %q", name)
+	}
+
+	// A custom selection restarts from the first preset
+	cfg := nav.GetConfig()
+	cfg.ShowSize = true
+	nav.SetConfig(cfg)
+	if name := nav.CycleViewPreset(); name != "names" {
+		t.Errorf("Expected a custom view to switch to names, got %q", name)
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	modTime := time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC)
+	tests := []struct {
+		format string
+		now    time.Time
+		want   string
+	}{
+		{DateFormatISO, modTime, "2024-03-09 14:05"},
+		{DateFormatRelative, modTime.Add(30 * time.Second), "just now"},
+		{DateFormatRelative, modTime.Add(5 * time.Minute), "5m ago"},
+		{DateFormatRelative, modTime.Add(2 * time.Hour), "2h ago"},
+		{DateFormatRelative, modTime.Add(3 * 24 * time.Hour), "3d ago"},
+		{DateFormatRelative, modTime.Add(65 * 24 * time.Hour), "2mo ago"},
+		{DateFormatRelative, modTime.Add(800 * 24 * time.Hour), "2y ago"},
+		{"Jan _2 15:04", modTime, "Mar  9 14:05"},
+		{"02/01/06", modTime, "09/03/24"},
+	}
+	for _, tt := range tests {
+		if got := formatDate(modTime, tt.format, tt.now); got != tt.want {
+			t.Errorf("formatDate(%q) expected %q, got %q", tt.format, tt.want, got)
+		}
+	}
+}
+
+func TestDisplaySize(t *testing.T) {
+	sparse := FileItem{Name: "disk.img", Size: 10 << 30, DiskSize: 4096}
+	unknown := FileItem{Name: "remote.bin", Size: 2048, DiskSize: -1}
+
+	cfg := DefaultConfig()
+	cfg.ShowSize = true
+	cell := func(item FileItem) string { return columnCell(item, ColumnSize, cfg, time.Time{}) }
+
+	if got := cell(sparse); got != "10.0G" {
+		t.Errorf("Expected apparent size 10.0G, got %s", got)
+	}
+
+	cfg.SizeOnDisk = true
+	if got := cell(sparse); got != "4.0K" {
+		t.Errorf("Expected on-disk size 4.0K, got %s", got)
+	}
+	// Without a block count the apparent size is shown
+	if got := cell(unknown); got != "2.0K" {
+		t.Errorf("Expected the apparent size fallback 2.0K, got %s", got)
+	}
+	if got := displaySize(FileItem{Size: 100, DiskSize: 0}, true); got != 0 {
+		t.Errorf("Expected an empty allocation to show 0, got %d", got)
+	}
+}
+
+func TestToggleSizeOnDisk(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	nav.ToggleSizeOnDisk()
+	if !nav.GetConfig().SizeOnDisk {
+		t.Error("Expected ToggleSizeOnDisk to switch to on-disk sizes")
+	}
+}
+
+func TestCompactRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		details string
+		width   int
+		want    string
+	}{
+		{"main.go", "4.2K  2h ago", 40, "main.go  4.2K  2h ago"},                // Wide: everything fits
+		{"main.go", "4.2K  2h ago", 21, "main.go  4.2K  2h ago"},                // Exactly fits
+		{"navigator_test.go", "4.2K  2h ago", 26, "naviga....go  4.2K  2h ago"}, // The name gives way
+		{"navigator_test.go", "4.2K  2h ago", 16, "navigator_....go"},           // Too narrow for details
+		{"../", "", 10, "../"},
+		{"résumé.pdf", "1K  now", 19, "résumé.pdf  1K  now"}, // Accents take one column each
+		{"日本語のメモ.txt", "1K  now", 25, "日本語のメモ.txt  1K  now"}, // Wide characters take two
+		{"日本語のメモ.txt", "1K  now", 22, "日本語....txt  1K  now"},
+	}
+	for _, tt := range tests {
+		got := compactRow(tt.name, tt.details, tt.width, "...")
+		if got != tt.want {
+			t.Errorf("compactRow(%q, %q, %d) = %q, want %q", tt.name, tt.details, tt.width, got, tt.want)
+		}
+		if w := runewidth.StringWidth(got); w > tt.width {
+			t.Errorf("compactRow(%q, %q, %d) is %d wide", tt.name, tt.details, tt.width, w)
+		}
+	}
+}
+
+func TestCompactDetails(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	file := FileItem{Name: "a.txt", Size: 1536, DiskSize: -1, ModTime: now.Add(-2 * time.Hour)}
+	dir := FileItem{Name: "sub", IsDir: true, ModTime: now.Add(-3 * 24 * time.Hour)}
+
+	if got := compactDetails(file, cfg, now); got != "1.5K  2h ago" {
+		t.Errorf("Expected size and age for a file, got %q", got)
+	}
+	if got := compactDetails(dir, cfg, now); got != "3d ago" {
+		t.Errorf("Expected only the age for a directory, got %q", got)
+	}
+	if got := compactDetails(FileItem{Name: "../", IsParent: true}, cfg, now); got != "" {
+		t.Errorf("Expected nothing for the parent entry, got %q", got)
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config holds user settings read from the config file and command-line flags.
+type Config struct {
+	ASCII              bool
+	WrapSiblings       bool
+	WrapHidden         bool // z and Z wrap around at the last/first hidden entry
+	MouseHover         bool
+	ExitPattern        string
+	MaxTerminals       int
+	BigDirEntries      int
+	ShowMounts         bool
+	OneFileSystem      bool // Size totals and trees stay on one filesystem
+	HistorySize        int
+	MaxDepth           int             // Levels recursive operations descend, 0 for no limit
+	PreserveAttributes bool            // Copies keep their source's mode and modification time
+	OnCollision        CollisionPolicy // What a move does about an existing entry of the same name
+	StashDir           string          // Where StashSelected copies items
+	SearchPaths        bool
+	SearchHighlight    bool
+	HideParent         bool
+	ScrollHints        bool   // Show "▲ N more" and "▼ N more" past the visible rows
+	ParentLabel        string // Shown instead of ../, with {name} for the parent's name
+	SelectFirstEntry   bool
+	TopGoesUp          bool // Up on the first entry goes to the parent
+	BottomEnters       bool // Down on a last entry that is a directory enters it
+	RememberView       bool // Restore each directory's selection and scroll on return
+	RefreshInterval    int
+	SortMode           SortMode
+	SortIgnoreCase     bool // Names sort like a dictionary: apple before Zebra
+	GroupSymlinks      bool
+	FollowSymlinks     bool // Enter descends into symlinked directories
+	SkipSingleChild    bool // Enter descends through directories holding only one subdirectory
+	OutputPath         string
+	Pick               bool // Exit printing the first file opened, or the directory picked with .
+	EditConfig         bool // --edit-config: open the config file in the editor instead of browsing
+	OpLogPath          string
+	OnSelect           string
+	ShowRealPath       bool
+	DetailsIDs         bool
+	EnterRules         string
+	NoExec             bool   // Never start terminals, apps, editors or commands
+	RenameStem         bool   // Rename edits the stem and keeps the extension
+	CopySeparator      string // Joins the paths copied by CopyMarkedPaths
+
+	Preview        bool
+	PreviewWrap    bool
+	PreviewImages  bool
+	TabWidth       int
+	PreviewSplit   int   // Percent of the width given to the list
+	PreviewMaxSize int64 // Bytes; larger files are not read, 0 for no limit
+
+	DirSlash        bool // Append / to directory names
+	CompactDetails  bool // Size and age after each name
+	ShowGit         bool // Git status letter before each name inside a repository
+	ShowPerms       bool
+	ShowSize        bool
+	SizeOnDisk      bool
+	ShowDate        bool
+	ColumnPadding   int
+	ColumnSeparator string
+	DateFormat      string
+
+	SelectionStyle string
+	SelectionFG    string
+	SelectionBG    string
+
+	Commands map[string]string // User command templates by name, see usercmd.go
+	Bindings map[rune]string   // User command names by key
+}
+
+// DefaultConfig returns the settings used when nothing is configured.
+func DefaultConfig() Config {
+	return Config{
+		MaxTerminals:       5,
+		DirSlash:           true,
+		FollowSymlinks:     true,
+		ParentLabel:        "../",
+		CopySeparator:      "\n",
+		HistorySize:        100,
+		MaxDepth:           defaultMaxDepth,
+		PreserveAttributes: true,
+		PreviewWrap:        true,
+		PreviewImages:      true,
+		TabWidth:           4,
+		PreviewSplit:       50,
+		PreviewMaxSize:     1 << 20,
+		ColumnPadding:      2,
+		DateFormat:         DateFormatISO,
+		SelectionStyle:     SelectionDefault,
+	}
+}
+
+// configPath returns the location of the config file, honoring $NAV_CONFIG.
+func configPath() (string, error) {
+	if path := os.Getenv("NAV_CONFIG"); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "nav", "config"), nil
+}
+
+// LoadConfig reads the config file at path. A missing file yields the defaults.
+func LoadConfig(path string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultConfig(), nil
+		}
+		return DefaultConfig(), err
+	}
+	defer file.Close()
+
+	return parseConfig(file)
+}
+
+// parseConfig parses "key = value" lines, skipping blank lines and # comments.
+func parseConfig(r io.Reader) (Config, error) {
+	cfg := DefaultConfig()
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return cfg, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+		if err := cfg.set(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return cfg, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+	}
+	return cfg, scanner.Err()
+}
+
+// set applies a single config setting.
+func (c *Config) set(key, value string) error {
+	if ok, err := c.setUserCommand(key, value); ok {
+		return err
+	}
+	switch key {
+	case "ascii":
+		return parseBool(value, &c.ASCII)
+	case "wrap_siblings":
+		return parseBool(value, &c.WrapSiblings)
+	case "wrap_hidden":
+		return parseBool(value, &c.WrapHidden)
+	case "mouse_hover":
+		return parseBool(value, &c.MouseHover)
+	case "exit_pattern":
+		return parsePattern(value, &c.ExitPattern)
+	case "max_terminals":
+		return parseInt(value, &c.MaxTerminals)
+	case "scroll_hints":
+		return parseBool(value, &c.ScrollHints)
+	case "hide_parent":
+		return parseBool(value, &c.HideParent)
+	case "top_goes_up":
+		return parseBool(value, &c.TopGoesUp)
+	case "bottom_enters":
+		return parseBool(value, &c.BottomEnters)
+	case "select_first_entry":
+		return parseBool(value, &c.SelectFirstEntry)
+	case "search_paths":
+		return parseBool(value, &c.SearchPaths)
+	case "max_depth":
+		return parseInt(value, &c.MaxDepth)
+	case "preserve_attributes":
+		return parseBool(value, &c.PreserveAttributes)
+	case "on_collision":
+		return parseCollisionPolicy(value, &c.OnCollision)
+	case "history_size":
+		return parseInt(value, &c.HistorySize)
+	case "big_dir_entries":
+		return parseInt(value, &c.BigDirEntries)
+	case "show_mounts":
+		return parseBool(value, &c.ShowMounts)
+	case "one_file_system":
+		return parseBool(value, &c.OneFileSystem)
+	case "no_exec":
+		return parseBool(value, &c.NoExec)
+	case "rename_stem":
+		return parseBool(value, &c.RenameStem)
+	case "remember_view":
+		return parseBool(value, &c.RememberView)
+	case "refresh_interval":
+		return parseInt(value, &c.RefreshInterval)
+	case "sort":
+		return parseSortMode(value, &c.SortMode)
+	case "skip_single_child":
+		return parseBool(value, &c.SkipSingleChild)
+	case "follow_symlinks":
+		return parseBool(value, &c.FollowSymlinks)
+	case "sort_ignore_case":
+		return parseBool(value, &c.SortIgnoreCase)
+	case "group_symlinks":
+		return parseBool(value, &c.GroupSymlinks)
+	case "search_highlight":
+		return parseBool(value, &c.SearchHighlight)
+	case "op_log":
+		c.OpLogPath = strings.Trim(value, `"`)
+		return nil
+	case "stash_dir":
+		return parsePath(value, &c.StashDir)
+	case "parent_label":
+		label := strings.Trim(value, `"`)
+		if label == "" {
+			return fmt.Errorf("parent_label must not be empty")
+		}
+		c.ParentLabel = label
+		return nil
+	case "on_select":
+		c.OnSelect = strings.Trim(value, `"`)
+		return nil
+	case "enter_rules":
+		return parseEnterRulesSetting(value, &c.EnterRules)
+	case "details_ids":
+		return parseBool(value, &c.DetailsIDs)
+	case "show_real_path":
+		return parseBool(value, &c.ShowRealPath)
+	case "preview":
+		return parseBool(value, &c.Preview)
+	case "preview_wrap":
+		return parseBool(value, &c.PreviewWrap)
+	case "preview_images":
+		return parseBool(value, &c.PreviewImages)
+	case "tab_width":
+		return parseInt(value, &c.TabWidth)
+	case "preview_max_size":
+		return parseSize(value, &c.PreviewMaxSize)
+	case "preview_split":
+		if err := parseInt(value, &c.PreviewSplit); err != nil {
+			return err
+		}
+		c.PreviewSplit = clampSplit(c.PreviewSplit)
+		return nil
+	case "dir_slash":
+		return parseBool(value, &c.DirSlash)
+	case "show_git":
+		return parseBool(value, &c.ShowGit)
+	case "compact_details":
+		return parseBool(value, &c.CompactDetails)
+	case "show_perms":
+		return parseBool(value, &c.ShowPerms)
+	case "show_size":
+		return parseBool(value, &c.ShowSize)
+	case "size_on_disk":
+		return parseBool(value, &c.SizeOnDisk)
+	case "show_date":
+		return parseBool(value, &c.ShowDate)
+	case "column_padding":
+		return parseInt(value, &c.ColumnPadding)
+	case "column_separator":
+		c.ColumnSeparator = strings.Trim(value, `"`)
+		return nil
+	case "copy_separator":
+		return parseCopySeparator(value, &c.CopySeparator)
+	case "date_format":
+		return parseDateFormat(strings.Trim(value, `"`), &c.DateFormat)
+	case "selection_style":
+		return parseSelectionStyle(value, &c.SelectionStyle)
+	case "selection_fg":
+		return parseColor(value, &c.SelectionFG)
+	case "selection_bg":
+		return parseColor(value, &c.SelectionBG)
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+}
+
+// parseBool parses a boolean config value into dst.
+func parseBool(value string, dst *bool) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+	*dst = b
+	return nil
+}
+
+// parsePath parses a file path config value into dst, expanding a leading ~
+// to the home directory. Relative paths are refused, since they would
+// depend on the directory nav happens to be started from. An empty value
+// clears the setting.
+func parsePath(value string, dst *string) error {
+	path := strings.Trim(value, `"`)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("cannot expand %q: %w", path, err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if path != "" && !filepath.IsAbs(path) {
+		return fmt.Errorf("path %q must be absolute or start with ~", path)
+	}
+	*dst = path
+	return nil
+}
+
+// parseInt parses a non-negative integer config value into dst.
+func parseInt(value string, dst *int) error {
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*dst = i
+	return nil
+}
+
+// parseDateFormat validates a built-in date format name or custom time layout into dst.
+func parseDateFormat(value string, dst *string) error {
+	if value != DateFormatISO && value != DateFormatRelative && !validDateLayout(value) {
+		return fmt.Errorf("invalid date format %q", value)
+	}
+	*dst = value
+	return nil
+}
+
+// parseSize parses a byte count with an optional K, M or G suffix, such as
+// "512K" or "2M", into dst.
+func parseSize(value string, dst *int64) error {
+	multiplier := int64(1)
+	number := strings.ToUpper(value)
+	switch {
+	case strings.HasSuffix(number, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(number, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(number, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		number = number[:len(number)-1]
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid size %q: expected bytes or a K, M or G suffix", value)
+	}
+	if n > math.MaxInt64/multiplier {
+		return fmt.Errorf("size %q is too large", value)
+	}
+	*dst = n * multiplier
+	return nil
+}
+
+// parsePattern validates a filepath.Match pattern into dst.
+func parsePattern(value string, dst *string) error {
+	if _, err := filepath.Match(value, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q", value)
+	}
+	*dst = value
+	return nil
+}
+
+// parseArgs applies command-line flags to cfg and returns the starting directory.
+func parseArgs(args []string, cfg *Config) (string, error) {
+	var startPath string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--ascii":
+			cfg.ASCII = true
+		case arg == "--no-exec":
+			cfg.NoExec = true
+		case arg == "--pick":
+			cfg.Pick = true
+		case arg == "--edit-config":
+			cfg.EditConfig = true
+		case arg == "--output":
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a path", arg)
+			}
+			i++
+			cfg.OutputPath = args[i]
+		case arg == "--exit-on":
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a pattern", arg)
+			}
+			i++
+			if err := parsePattern(args[i], &cfg.ExitPattern); err != nil {
+				return "", err
+			}
+		case strings.HasPrefix(arg, "-"):
+			return "", fmt.Errorf("unknown flag: %s", arg)
+		default:
+			startPath = arg
+		}
+	}
+	return startPath, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	input := `
+# display
+ascii = true
+
+wrap_siblings = 1
+`
+	cfg, err := parseConfig(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	if !cfg.ASCII || !cfg.WrapSiblings {
+		t.Errorf("parseConfig did not apply settings, got %+v", cfg)
+	}
+
+	cfg, err = parseConfig(strings.NewReader("column_padding = 1\ncolumn_separator = \" | \"\n"))
+	if err != nil || cfg.ColumnPadding != 1 || cfg.ColumnSeparator != " | " {
+		t.Errorf("parseConfig column settings = %d %q (err %v)", cfg.ColumnPadding, cfg.ColumnSeparator, err)
+	}
+
+	cfg, err = parseConfig(strings.NewReader("date_format = \"Jan _2 15:04\"\n"))
+	if err != nil || cfg.DateFormat != "Jan _2 15:04" {
+		t.Errorf("parseConfig date_format = %q (err %v)", cfg.DateFormat, err)
+	}
+	if _, err := parseConfig(strings.NewReader("date_format = yesterday")); err == nil {
+		t.Error("parseConfig accepted a date layout without time elements")
+	}
+
+	cfg, err = parseConfig(strings.NewReader("preview_max_size = 512K\n"))
+	if err != nil || cfg.PreviewMaxSize != 512<<10 {
+		t.Errorf("parseConfig preview_max_size = %d (err %v)", cfg.PreviewMaxSize, err)
+	}
+	if _, err := parseConfig(strings.NewReader("preview_max_size = 1T")); err == nil {
+		t.Error("parseConfig accepted an invalid size")
+	}
+	if _, err := parseConfig(strings.NewReader("preview_max_size = 99999999999G")); err == nil {
+		t.Error("parseConfig accepted a size that overflows")
+	}
+
+	if _, err := parseConfig(strings.NewReader("ascii = maybe")); err == nil {
+		t.Error("parseConfig accepted an invalid boolean")
+	}
+	if _, err := parseConfig(strings.NewReader("bogus = 1")); err == nil {
+		t.Error("parseConfig accepted an unknown setting")
+	}
+	if _, err := parseConfig(strings.NewReader("ascii")); err == nil {
+		t.Error("parseConfig accepted a line without '='")
+	}
+}
+
+func TestParseConfigPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	cfg, err := parseConfig(strings.NewReader("stash_dir = \"~/my stash\"\nop_log = ops.log\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	if want := filepath.Join(home, "my stash"); cfg.StashDir != want {
+		t.Errorf("Expected stash_dir %s, got %s", want, cfg.StashDir)
+	}
+	// op_log is taken as written, so a relative one stays relative
+	if cfg.OpLogPath != "ops.log" {
+		t.Errorf("Expected op_log ops.log, got %s", cfg.OpLogPath)
+	}
+
+	absolute := filepath.Join(home, "elsewhere")
+	if cfg, err := parseConfig(strings.NewReader("stash_dir = " + absolute)); err != nil || cfg.StashDir != absolute {
+		t.Errorf("Expected an absolute stash_dir kept, got %q (err %v)", cfg.StashDir, err)
+	}
+	for _, input := range []string{"stash_dir = stash", "stash_dir = ./stash", "stash_dir = ~other/stash"} {
+		if _, err := parseConfig(strings.NewReader(input)); err == nil {
+			t.Errorf("parseConfig accepted the relative path in %q", input)
+		}
+	}
+
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	t.Setenv("home", "")
+	if _, err := parseConfig(strings.NewReader("stash_dir = ~/stash")); err == nil {
+		t.Error("parseConfig expanded ~ without a home directory")
+	}
+}
+
+func TestConfigPathWithoutHome(t *testing.T) {
+	t.Setenv("NAV_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "")
+	t.Setenv("APPDATA", "")
+	t.Setenv("home", "")
+
+	// The caller falls back to the defaults, so only an error is expected here
+	if path, err := configPath(); err == nil {
+		t.Errorf("Expected an error without a home directory, got %q", path)
+	}
+
+	t.Setenv("NAV_CONFIG", "/etc/nav.conf")
+	if path, err := configPath(); err != nil || path != "/etc/nav.conf" {
+		t.Errorf("Expected $NAV_CONFIG to work without a home, got %q (err %v)", path, err)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Errorf("LoadConfig failed for a missing file: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("LoadConfig for a missing file expected defaults, got %+v", cfg)
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(path, []byte("ascii = true\n"), 0644)
+	cfg, err = LoadConfig(path)
+	if err != nil || !cfg.ASCII {
+		t.Errorf("LoadConfig expected ascii enabled, got %+v (err %v)", cfg, err)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	cfg := DefaultConfig()
+	startPath, err := parseArgs([]string{"--ascii", "/tmp"}, &cfg)
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	if startPath != "/tmp" {
+		t.Errorf("parseArgs expected start path /tmp, got %q", startPath)
+	}
+	if !cfg.ASCII {
+		t.Error("parseArgs did not enable ASCII mode")
+	}
+
+	cfg = DefaultConfig()
+	if _, err := parseArgs([]string{"--pick"}, &cfg); err != nil || !cfg.Pick {
+		t.Errorf("parseArgs --pick expected Pick, got %v (err %v)", cfg.Pick, err)
+	}
+
+	cfg = DefaultConfig()
+	if _, err := parseArgs([]string{"--ascii", "--edit-config"}, &cfg); err != nil || !cfg.EditConfig || !cfg.ASCII {
+		t.Errorf("parseArgs --edit-config after another flag expected EditConfig, got %v (err %v)", cfg.EditConfig, err)
+	}
+
+	cfg = DefaultConfig()
+	if _, err := parseArgs([]string{"--no-exec"}, &cfg); err != nil || !cfg.NoExec {
+		t.Errorf("parseArgs --no-exec expected NoExec, got %v (err %v)", cfg.NoExec, err)
+	}
+
+	cfg = DefaultConfig()
+	startPath, _ = parseArgs(nil, &cfg)
+	if startPath != "" {
+		t.Errorf("parseArgs expected no start path, got %q", startPath)
+	}
+
+	cfg = DefaultConfig()
+	if _, err := parseArgs([]string{"--exit-on", "wt-*"}, &cfg); err != nil || cfg.ExitPattern != "wt-*" {
+		t.Errorf("parseArgs --exit-on expected pattern wt-*, got %q (err %v)", cfg.ExitPattern, err)
+	}
+	if _, err := parseArgs([]string{"--exit-on", "["}, &cfg); err == nil {
+		t.Error("parseArgs accepted an invalid --exit-on pattern")
+	}
+	if _, err := parseArgs([]string{"--exit-on"}, &cfg); err == nil {
+		t.Error("parseArgs accepted --exit-on without a pattern")
+	}
+
+	if _, err := parseArgs([]string{"--output", "/tmp/fifo"}, &cfg); err != nil || cfg.OutputPath != "/tmp/fifo" {
+		t.Errorf("parseArgs --output expected /tmp/fifo, got %q (err %v)", cfg.OutputPath, err)
+	}
+	if _, err := parseArgs([]string{"--output"}, &cfg); err == nil {
+		t.Error("parseArgs accepted --output without a path")
+	}
+
+	if _, err := parseArgs([]string{"--bogus"}, &cfg); err == nil {
+		t.Error("parseArgs accepted an unknown flag")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// copyPath copies src to dst, descending into directories at most maxDepth
+// levels and recreating symlinks rather than following them. It never
+// replaces an existing entry. With preserve, like cp -p, files and
+// directories keep the mode and modification time of their source; without
+// it they get the default permissions and the time of the copy. With oneFS,
+// like cp -x, directories on other filesystems are copied empty. A failed
+// copy removes what it created, but never an entry it found at dst.
+func copyPath(src, dst string, maxDepth int, preserve, oneFS bool) (err error) {
+	var dirs []copiedDir
+	copyOne := func(src, dst string) (os.FileInfo, error) {
+		info, err := copyEntry(src, dst, preserve)
+		if err == nil && preserve && info.IsDir() {
+			dirs = append(dirs, copiedDir{dst, info})
+		}
+		return info, err
+	}
+
+	// copyEntry cleans up after itself, so only a directory it made is left
+	if info, err := copyOne(src, dst); err != nil || !info.IsDir() {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dst)
+		}
+	}()
+	err = walkTree(osSource{mounts: oneFS}, src, maxDepth, func(entry walkEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, entry.Path)
+		if err != nil {
+			return err
+		}
+		if _, err := copyOne(entry.Path, filepath.Join(dst, rel)); err != nil {
+			return err
+		}
+		if oneFS && entry.MountPoint {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Filling a directory in changes its time, and a read-only one could not
+	// be filled in at all, so directories get theirs last, deepest first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := preserveAttributes(dirs[i].path, dirs[i].info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copiedDir is a directory created by copyPath and the source it came from.
+type copiedDir struct {
+	path string
+	info os.FileInfo
+}
+
+// copyEntry copies src to dst on its own: a directory is created empty and a
+// symlink is recreated. It returns what src was. A copied file keeps its
+// source's mode and time with preserve; a directory is left writable for its
+// contents, and its attributes are for the caller to restore. When it fails,
+// dst is left as it was found.
+func copyEntry(src, dst string, preserve bool) (os.FileInfo, error) {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return nil, err
+	}
+
+	switch fileTypeOf(info.Mode()) {
+	case TypeSymlink:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return info, err
+		}
+		return info, os.Symlink(target, dst)
+	case TypeDir:
+		perm := os.FileMode(0777)
+		if preserve {
+			perm = info.Mode().Perm() | 0700
+		}
+		return info, os.Mkdir(dst, perm)
+	case TypeRegular:
+		if !preserve {
+			return info, copyFile(src, dst, 0666)
+		}
+		if err := copyFile(src, dst, info.Mode().Perm()); err != nil {
+			return info, err
+		}
+		if err := preserveAttributes(dst, info); err != nil {
+			os.Remove(dst)
+			return info, err
+		}
+		return info, nil
+	default:
+		return info, fmt.Errorf("%s: cannot copy a %s", filepath.Base(src), fileTypeOf(info.Mode()))
+	}
+}
+
+// preserveAttributes gives path the mode and modification time in info. The
+// mode is set explicitly since the umask narrows the one a file is created
+// with.
+func preserveAttributes(path string, info os.FileInfo) error {
+	mode := info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	if err := os.Chmod(path, mode); err != nil {
+		return err
+	}
+	return os.Chtimes(path, time.Time{}, info.ModTime())
+}
+
+// copyFile copies the contents of the regular file src to a new file dst,
+// removing it again when the copy fails.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
+
+// movePath moves src to dst. Across filesystems, where a rename fails with
+// EXDEV, it copies src and then removes it; a failed copy leaves src
+// untouched. preserve is passed on to copyPath. With oneFS a directory
+// holding another filesystem is refused rather than copied, as removing it
+// afterwards would reach into that filesystem.
+func movePath(src, dst string, maxDepth int, preserve, oneFS bool) error {
+	err := renameFile(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if oneFS {
+		mount, err := findMountPoint(src, maxDepth)
+		if err != nil {
+			return err
+		}
+		if mount != "" {
+			return fmt.Errorf("%s: %s is on another filesystem (one_file_system)", filepath.Base(src), mount)
+		}
+	}
+	if err := copyPath(src, dst, maxDepth, preserve, oneFS); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// crossesDevice reports whether path and dir are on different filesystems. It
+// reports false when the platform does not provide device numbers.
+func crossesDevice(path, dir string) bool {
+	pathInfo, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	_, pathDevice, _, ok := fileIDs(pathInfo)
+	_, dirDevice, _, dirOK := fileIDs(dirInfo)
+	return ok && dirOK && pathDevice != dirDevice
+}
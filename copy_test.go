@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCopyPath(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("alpha"), 0644)
+	os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("beta"), 0600)
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := copyPath(src, dst, defaultMaxDepth, true, false); err != nil {
+		t.Fatalf("copyPath failed: %v", err)
+	}
+	for name, want := range map[string]string{"a.txt": "alpha", "sub/b.txt": "beta"} {
+		data, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil || string(data) != want {
+			t.Errorf("Expected %s to contain %q, got %q (err %v)", name, want, data, err)
+		}
+	}
+
+	// An existing destination is never replaced
+	if err := copyPath(filepath.Join(src, "a.txt"), filepath.Join(dst, "sub", "b.txt"), defaultMaxDepth, true, false); err == nil {
+		t.Error("Expected copyPath to refuse an existing destination")
+	}
+}
+
+func TestMovePathCrossDevice(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "project")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "notes.txt"), []byte("notes"), 0644)
+	dst := filepath.Join(t.TempDir(), "project")
+
+	renames := 0
+	renameFile = func(oldpath, newpath string) error {
+		renames++
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	defer func() { renameFile = os.Rename }()
+
+	if err := movePath(src, dst, defaultMaxDepth, true, false); err != nil {
+		t.Fatalf("movePath failed: %v", err)
+	}
+	if renames != 1 {
+		t.Errorf("Expected a rename attempt before copying, got %d", renames)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "sub", "notes.txt")); err != nil || string(data) != "notes" {
+		t.Errorf("Expected the copy to hold the contents, got %q (err %v)", data, err)
+	}
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected the source to be removed after copying, got %v", err)
+	}
+}
+
+func TestMovePathCopyFailureKeepsSource(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	os.WriteFile(src, []byte("alpha"), 0644)
+
+	renameFile = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	defer func() { renameFile = os.Rename }()
+
+	// The copy fails because the destination directory is missing
+	if err := movePath(src, filepath.Join(dir, "missing", "a.txt"), defaultMaxDepth, true, false); err == nil {
+		t.Fatal("Expected the failed copy to be reported")
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("Expected the source to survive a failed copy, got %v", err)
+	}
+}
+
+func TestMovePathKeepsExistingDestination(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	os.Mkdir(src, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("alpha"), 0644)
+	// dst appears after the caller checked for it
+	os.Mkdir(dst, 0755)
+	os.WriteFile(filepath.Join(dst, "keep.txt"), []byte("mine"), 0644)
+
+	renameFile = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	defer func() { renameFile = os.Rename }()
+
+	if err := movePath(src, dst, defaultMaxDepth, true, false); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("Expected the copy to refuse an existing destination, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "keep.txt")); err != nil || string(data) != "mine" {
+		t.Errorf("Expected the existing destination to be left alone, got %q (err %v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(src, "a.txt")); err != nil {
+		t.Errorf("Expected the source to survive, got %v", err)
+	}
+}
+
+func TestMovePathOtherErrors(t *testing.T) {
+	copied := filepath.Join(t.TempDir(), "dst")
+	renameFile = func(oldpath, newpath string) error {
+		return os.ErrPermission
+	}
+	defer func() { renameFile = os.Rename }()
+
+	src := filepath.Join(t.TempDir(), "src")
+	os.WriteFile(src, []byte("x"), 0644)
+	if err := movePath(src, copied, defaultMaxDepth, true, false); err != os.ErrPermission {
+		t.Errorf("Expected other rename errors to be returned as is, got %v", err)
+	}
+	if _, err := os.Lstat(copied); !os.IsNotExist(err) {
+		t.Error("Expected no copy for errors other than EXDEV")
+	}
+}
+
+func TestMoveItems(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	os.WriteFile(filepath.Join(tempDir, "dir2", "file1.txt"), []byte("existing"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("notes"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "notes.txt")
+	nav.ToggleMark()
+	selectByName(t, nav, "file1.txt")
+	nav.ToggleMark()
+
+	if copies, err := nav.MoveNeedsCopy("dir2"); err != nil || copies != 0 {
+		t.Errorf("Expected a move within one filesystem to need no copies, got %d (err %v)", copies, err)
+	}
+
+	// file1.txt collides in dir2 and stays; notes.txt moves
+	if err := nav.MoveItems("dir2", nil); err == nil {
+		t.Error("Expected the collision to be reported")
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "dir2", "notes.txt")); string(data) != "notes" {
+		t.Errorf("Expected notes.txt in dir2, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "dir2", "file1.txt")); string(data) != "existing" {
+		t.Errorf("Expected the existing file1.txt to be kept, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "file1.txt")); err != nil {
+		t.Errorf("Expected file1.txt to stay after the collision: %v", err)
+	}
+	if got := len(nav.GetMarkedItems()); got != 1 {
+		t.Errorf("Expected only the item that failed to stay marked, got %d", got)
+	}
+
+	if _, err := nav.MoveNeedsCopy("file1.txt"); err == nil {
+		t.Error("Expected a file destination to be rejected")
+	}
+}
+
+func TestCopyPathPreservesAttributes(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "script.sh"), []byte("#!/bin/sh"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "secret.txt"), []byte("secret"), 0600)
+	old := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, name := range []string{"script.sh", "sub/secret.txt", "sub", "."} {
+		os.Chtimes(filepath.Join(src, filepath.FromSlash(name)), old, old)
+	}
+	// A read-only directory still gets its contents copied
+	os.Chmod(filepath.Join(src, "sub"), 0555)
+	defer os.Chmod(filepath.Join(src, "sub"), 0755)
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := copyPath(src, dst, defaultMaxDepth, true, false); err != nil {
+		t.Fatalf("copyPath failed: %v", err)
+	}
+	defer os.Chmod(filepath.Join(dst, "sub"), 0755)
+
+	for _, name := range []string{"script.sh", "sub/secret.txt", "sub", "."} {
+		srcInfo, _ := os.Stat(filepath.Join(src, filepath.FromSlash(name)))
+		info, err := os.Stat(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Expected %s to be copied: %v", name, err)
+		}
+		if runtime.GOOS != "windows" && info.Mode() != srcInfo.Mode() {
+			t.Errorf("Expected %s to keep mode %v, got %v", name, srcInfo.Mode(), info.Mode())
+		}
+		if !info.ModTime().Equal(old) {
+			t.Errorf("Expected %s to keep its time %v, got %v", name, old, info.ModTime())
+		}
+	}
+
+	// Without preserve, copies are new files
+	plain := filepath.Join(t.TempDir(), "plain.sh")
+	if err := copyPath(filepath.Join(src, "script.sh"), plain, defaultMaxDepth, false, false); err != nil {
+		t.Fatalf("copyPath failed: %v", err)
+	}
+	if info, _ := os.Stat(plain); info.ModTime().Equal(old) {
+		t.Error("Expected a copy without preserve to get the current time")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// ItemDetails describes the selected item for the details popup.
+type ItemDetails struct {
+	Name       string
+	Path       string
+	Type       FileType
+	Size       int64
+	Mode       os.FileMode
+	ModTime    time.Time
+	LinkTarget string
+
+	// Inode, Device and Links are only set where the platform reports them
+	HasIDs bool
+	Inode  uint64
+	Device uint64
+	Links  uint64
+}
+
+// ToggleDetails opens or closes the details popup for the selected item.
+func (n *Navigator) ToggleDetails() {
+	n.detailsOpen = !n.detailsOpen
+}
+
+// IsDetailsOpen reports whether the details popup is shown.
+func (n *Navigator) IsDetailsOpen() bool {
+	return n.detailsOpen
+}
+
+// ToggleDetailsIDs shows or hides inode and device numbers in the details popup.
+func (n *Navigator) ToggleDetailsIDs() {
+	n.config.DetailsIDs = !n.config.DetailsIDs
+}
+
+// SelectedDetails returns the details of the selected item. They are cached
+// until the selection or the listing changes.
+func (n *Navigator) SelectedDetails() (*ItemDetails, error) {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil {
+		return nil, nil
+	}
+	if n.details != nil && n.details.Path == selectedItem.Path {
+		return n.details, nil
+	}
+
+	info, err := n.source.Stat(selectedItem.Path)
+	if err != nil {
+		return nil, err
+	}
+	details := &ItemDetails{
+		Name:    selectedItem.Name,
+		Path:    selectedItem.Path,
+		Type:    selectedItem.Type,
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+	}
+	if links, ok := n.source.(linkReader); ok && selectedItem.Type == TypeSymlink {
+		details.LinkTarget, _ = links.Readlink(selectedItem.Path)
+	}
+	details.Inode, details.Device, details.Links, details.HasIDs = fileIDs(info)
+
+	n.details = details
+	return details, nil
+}
+
+// detailsLines formats details for display, including inode and device
+// numbers when showIDs is set and the platform provides them.
+func detailsLines(details *ItemDetails, showIDs bool) []string {
+	lines := []string{
+		"Name:     " + details.Name,
+		"Path:     " + details.Path,
+		"Type:     " + details.Type.String(),
+	}
+	if details.LinkTarget != "" {
+		lines = append(lines, "Target:   "+details.LinkTarget)
+	}
+	lines = append(lines,
+		fmt.Sprintf("Size:     %s (%d bytes)", formatSize(details.Size), details.Size),
+		"Mode:     "+details.Mode.String(),
+		"Modified: "+details.ModTime.Format("2006-01-02 15:04:05"),
+	)
+	if showIDs && details.HasIDs {
+		lines = append(lines,
+			fmt.Sprintf("Inode:    %d", details.Inode),
+			fmt.Sprintf("Device:   %d", details.Device),
+			fmt.Sprintf("Links:    %d", details.Links),
+		)
+	}
+	return lines
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dirView is the part of the view that can be remembered for a directory:
+// the sort order and which columns are shown.
+type dirView struct {
+	Sort           string `json:"sort"`
+	SortIgnoreCase bool   `json:"sort_ignore_case,omitempty"`
+	ShowPerms      bool   `json:"show_perms,omitempty"`
+	ShowSize       bool   `json:"show_size,omitempty"`
+	ShowDate       bool   `json:"show_date,omitempty"`
+	CompactDetails bool   `json:"compact_details,omitempty"`
+}
+
+// viewOf returns the remembered part of cfg's view.
+func viewOf(cfg Config) dirView {
+	return dirView{
+		Sort:           cfg.SortMode.String(),
+		SortIgnoreCase: cfg.SortIgnoreCase,
+		ShowPerms:      cfg.ShowPerms,
+		ShowSize:       cfg.ShowSize,
+		ShowDate:       cfg.ShowDate,
+		CompactDetails: cfg.CompactDetails,
+	}
+}
+
+// apply sets cfg's view to v. An unknown sort mode, say from a newer version,
+// leaves the sort mode as it was.
+func (v dirView) apply(cfg *Config) {
+	parseSortMode(v.Sort, &cfg.SortMode)
+	cfg.SortIgnoreCase = v.SortIgnoreCase
+	cfg.ShowPerms, cfg.ShowSize, cfg.ShowDate = v.ShowPerms, v.ShowSize, v.ShowDate
+	cfg.CompactDetails = v.CompactDetails
+}
+
+// defaultViewsFile returns the file holding the remembered directory views,
+// kept next to the config file.
+func defaultViewsFile() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "views.json"), nil
+}
+
+// loadDirViews reads the remembered views, keyed by directory, from path. A
+// missing file holds none.
+func loadDirViews(path string) (map[string]dirView, error) {
+	views := make(map[string]dirView)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return views, nil
+	}
+	if err != nil {
+		return views, err
+	}
+	if err := json.Unmarshal(data, &views); err != nil {
+		return make(map[string]dirView), fmt.Errorf("%s: %w", path, err)
+	}
+	return views, nil
+}
+
+// saveDirViews writes views to path, replacing the file whole.
+func saveDirViews(path string, views map[string]dirView) error {
+	data, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return replaceFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// SetViewsFile sets the file directory views are remembered in. Without one,
+// views are neither remembered nor applied.
+func (n *Navigator) SetViewsFile(path string) {
+	n.viewsFile = path
+	n.dirViews = nil
+	n.dirViewPath = ""
+}
+
+// loadedDirViews returns the remembered views, reading them on first use.
+func (n *Navigator) loadedDirViews() map[string]dirView {
+	if n.dirViews == nil {
+		views, err := loadDirViews(n.viewsFile)
+		if err != nil {
+			n.SetStatusMessage(fmt.Sprintf("Cannot read remembered views: %v", err))
+		}
+		n.dirViews = views
+	}
+	return n.dirViews
+}
+
+// applyDirView switches to the view remembered for the current directory on
+// the first scan after entering it. Leaving for a directory with none goes
+// back to the view shown before, so a date-sorted downloads directory does
+// not sort everything else by date.
+func (n *Navigator) applyDirView() {
+	if n.viewsFile == "" || n.currentPath == n.dirViewPath {
+		return
+	}
+	n.dirViewPath = n.currentPath
+
+	if view, ok := n.loadedDirViews()[n.currentPath]; ok {
+		if n.viewBeforeDir == nil {
+			before := viewOf(n.config)
+			n.viewBeforeDir = &before
+		}
+		view.apply(&n.config)
+		return
+	}
+	if n.viewBeforeDir != nil {
+		n.viewBeforeDir.apply(&n.config)
+		n.viewBeforeDir = nil
+	}
+}
+
+// ToggleDirView remembers the current view for the current directory, or
+// forgets it when one is remembered already, and saves the change. It
+// reports whether a view is now remembered.
+func (n *Navigator) ToggleDirView() (bool, error) {
+	if n.viewsFile == "" {
+		return false, fmt.Errorf("no file to remember views in")
+	}
+	views := n.loadedDirViews()
+	_, remembered := views[n.currentPath]
+	if remembered {
+		delete(views, n.currentPath)
+	} else {
+		views[n.currentPath] = viewOf(n.config)
+		if n.viewBeforeDir == nil {
+			before := viewOf(n.config)
+			n.viewBeforeDir = &before
+		}
+	}
+	return !remembered, saveDirViews(n.viewsFile, views)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirViewRememberedAndReapplied(t *testing.T) {
+	viewsFile := filepath.Join(t.TempDir(), "nav", "views.json")
+	root := t.TempDir()
+	downloads := filepath.Join(root, "downloads")
+	os.Mkdir(downloads, 0755)
+	os.Mkdir(filepath.Join(root, "src"), 0755)
+
+	nav, _ := NewNavigator(downloads)
+	nav.SetViewsFile(viewsFile)
+	nav.ScanDirectory()
+
+	// Remember the extension sort and the long view for downloads
+	nav.CycleSortMode()
+	nav.CycleViewPreset()
+	if remembered, err := nav.ToggleDirView(); err != nil || !remembered {
+		t.Fatalf("Expected the view to be remembered, got %v (err %v)", remembered, err)
+	}
+	if _, err := os.Stat(viewsFile); err != nil {
+		t.Fatalf("Expected the views file to be written: %v", err)
+	}
+
+	// Another directory goes back to the view from before
+	nav.GoToPath(filepath.Join(root, "src"))
+	if cfg := nav.GetConfig(); cfg.SortMode != SortByExtension || !cfg.ShowSize {
+		t.Fatalf("Expected the view shown before remembering to carry on, got %v", cfg.SortMode)
+	}
+	nav.CycleSortMode() // unsorted
+	nav.CycleSortMode() // name
+	nav.CycleViewPreset()
+	nav.GoToPath(downloads)
+	if cfg := nav.GetConfig(); cfg.SortMode != SortByExtension || !cfg.ShowSize || !cfg.ShowDate {
+		t.Errorf("Expected the remembered view on return, got sort %v, size %v", cfg.SortMode, cfg.ShowSize)
+	}
+	nav.GoToPath(filepath.Join(root, "src"))
+	if cfg := nav.GetConfig(); cfg.SortMode != SortByName || cfg.ShowSize {
+		t.Errorf("Expected leaving to restore the name sort without columns, got sort %v, size %v", cfg.SortMode, cfg.ShowSize)
+	}
+
+	// A new session reads the file and applies the view on the first scan
+	next, _ := NewNavigator(downloads)
+	next.SetViewsFile(viewsFile)
+	next.ScanDirectory()
+	if cfg := next.GetConfig(); cfg.SortMode != SortByExtension || !cfg.ShowPerms {
+		t.Errorf("Expected the saved view in a new session, got sort %v, perms %v", cfg.SortMode, cfg.ShowPerms)
+	}
+
+	// Forgetting it is saved too
+	if remembered, err := next.ToggleDirView(); err != nil || remembered {
+		t.Errorf("Expected the view to be forgotten, got %v (err %v)", remembered, err)
+	}
+	if views, err := loadDirViews(viewsFile); err != nil || len(views) != 0 {
+		t.Errorf("Expected no remembered views, got %v (err %v)", views, err)
+	}
+}
+
+func TestSaveDirViewsReplacesWhole(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "views.json")
+	first := map[string]dirView{"/a": {Sort: "name"}}
+	second := map[string]dirView{"/b": {Sort: "extension"}}
+	if err := saveDirViews(path, first); err != nil {
+		t.Fatalf("saveDirViews failed: %v", err)
+	}
+	if err := saveDirViews(path, second); err != nil {
+		t.Fatalf("saveDirViews failed to replace the file: %v", err)
+	}
+	if views, err := loadDirViews(path); err != nil || !reflect.DeepEqual(views, second) {
+		t.Errorf("Expected %v, got %v (err %v)", second, views, err)
+	}
+
+	// A failed rename leaves the old file and no temporary file behind
+	renameFile = func(oldpath, newpath string) error {
+		return errors.New("simulated crash")
+	}
+	defer func() { renameFile = os.Rename }()
+	if err := saveDirViews(path, first); err == nil {
+		t.Fatal("Expected the simulated rename failure to be returned")
+	}
+	if views, _ := loadDirViews(path); !reflect.DeepEqual(views, second) {
+		t.Errorf("Expected the previous views kept, got %v", views)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only views.json to remain, found %d entries", len(entries))
+	}
+}
+
+func TestDirViewWithoutFile(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	nav.ScanDirectory()
+	if _, err := nav.ToggleDirView(); err == nil {
+		t.Error("Expected an error without a views file")
+	}
+	if views, err := loadDirViews(filepath.Join(t.TempDir(), "missing.json")); err != nil || len(views) != 0 {
+		t.Errorf("Expected a missing file to hold no views, got %v (err %v)", views, err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// configTemplate is written to a new config file so there is something to
+// start from. Every setting is commented out, leaving the defaults in place.
+const configTemplate = `# nav settings, one "key = value" per line. Lines starting with # are
+# comments; remove the # in front of a setting to change it.
+# Run nav --help to see every setting.
+
+# Draw the tree and truncated names with ASCII only
+# ascii = true
+
+# Show the preview pane on startup, giving the list this percent of the width
+# preview = true
+# preview_split = 50
+
+# Show permissions, size and modification date columns
+# show_perms = true
+# show_size = true
+# show_date = true
+
+# Initial sort mode: name, extension or unsorted
+# sort = name
+
+# Omit the ../ entry, or label it differently
+# hide_parent = true
+# parent_label = ..
+
+# What Enter does for a file, by extension or mime type
+# enter_rules = md:edit, image/*:open, *:terminal
+
+# Commands run through the shell in the current directory, bound to keys nav
+# leaves free; {path}, {name} and {dir} stand for the selection and directory
+# command.gitlog = git log -- {path}
+# bind.G = gitlog
+`
+
+// ensureConfigFile returns the config file's path, first creating it from
+// configTemplate when it does not exist. An existing file is left untouched.
+func ensureConfigFile() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(path, []byte(configTemplate), 0644); err != nil && !errors.Is(err, fs.ErrExist) {
+		return "", err
+	}
+	return path, nil
+}
+
+// editConfigCommand returns the command opening the config file at path in
+// the user's editor.
+func editConfigCommand(path string) *exec.Cmd {
+	editor := editorCommand()
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
+
+// EditConfig opens the config file in the editor, creating it from a template
+// when needed. Changes apply the next time nav starts.
+func (n *Navigator) EditConfig() error {
+	if n.execDisabled() {
+		return nil
+	}
+	path, err := ensureConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := n.runForeground(editConfigCommand(path)); err != nil {
+		return err
+	}
+	n.SetStatusMessage("Edited " + path + "; restart nav to apply the changes")
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestConfigPathResolution(t *testing.T) {
+	t.Setenv("NAV_CONFIG", "/etc/nav.conf")
+	if path, err := configPath(); err != nil || path != "/etc/nav.conf" {
+		t.Errorf("Expected $NAV_CONFIG to win, got %q (err %v)", path, err)
+	}
+
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "plan9" {
+		t.Skip("$XDG_CONFIG_HOME is only used on Unix")
+	}
+	configHome := t.TempDir()
+	t.Setenv("NAV_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	if path, err := configPath(); err != nil || path != filepath.Join(configHome, "nav", "config") {
+		t.Errorf("Expected the config below $XDG_CONFIG_HOME, got %q (err %v)", path, err)
+	}
+}
+
+func TestEnsureConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nav", "config")
+	t.Setenv("NAV_CONFIG", path)
+
+	got, err := ensureConfigFile()
+	if err != nil || got != path {
+		t.Fatalf("Expected the config created at %s, got %q (err %v)", path, got, err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != configTemplate {
+		t.Errorf("Expected the template in a new config, got %q", data)
+	}
+
+	// The template leaves every setting at its default
+	if cfg, err := LoadConfig(path); err != nil || !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("Expected the template to load as the defaults, got %+v (err %v)", cfg, err)
+	}
+
+	// An existing config is never replaced
+	os.WriteFile(path, []byte("ascii = true\n"), 0644)
+	if _, err := ensureConfigFile(); err != nil {
+		t.Fatalf("ensureConfigFile failed for an existing file: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "ascii = true\n" {
+		t.Errorf("Expected the existing config kept, got %q", data)
+	}
+}
+
+func TestEditConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("NAV_CONFIG", path)
+	t.Setenv("VISUAL", "fake-editor -w")
+
+	nav, _ := NewNavigator(t.TempDir())
+	var ran []string
+	nav.SetForegroundRunner(func(cmd *exec.Cmd) error {
+		ran = cmd.Args
+		return nil
+	})
+	if err := nav.EditConfig(); err != nil {
+		t.Fatalf("EditConfig failed: %v", err)
+	}
+	if len(ran) != 3 || ran[0] != "fake-editor" || ran[2] != path {
+		t.Errorf("Expected the editor to open %s, got %q", path, ran)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the config to be created: %v", err)
+	}
+}
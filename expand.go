@@ -0,0 +1,83 @@
+package main
+
+import "path/filepath"
+
+// expandTree returns items with the contents of every expanded directory
+// listed beneath it, one level deeper and sorted like the listing. Items are
+// given depth, their children depth+1 and so on. Directories that can no
+// longer be read are shown collapsed.
+func (n *Navigator) expandTree(items []FileItem, depth int) []FileItem {
+	tree := make([]FileItem, 0, len(items))
+	for _, item := range items {
+		item.Depth = depth
+		tree = append(tree, item)
+		if !n.isExpanded(item) {
+			continue
+		}
+		children, err := n.readDir(item.Path)
+		if err != nil {
+			continue
+		}
+		n.sortList(children)
+		tree = append(tree, n.expandTree(children, depth+1)...)
+	}
+	return tree
+}
+
+// isExpanded reports whether item is a directory shown expanded in place.
+func (n *Navigator) isExpanded(item FileItem) bool {
+	return item.IsDir && !item.IsParent && n.expanded[item.Path]
+}
+
+// ExpandSelected lists the selected directory's contents beneath it, indented,
+// without leaving the current directory.
+func (n *Navigator) ExpandSelected() error {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil || !selectedItem.IsDir || selectedItem.IsParent || n.isExpanded(*selectedItem) {
+		return nil
+	}
+	// Reading it first reports an unreadable directory instead of expanding to nothing
+	if _, err := n.readDir(selectedItem.Path); err != nil {
+		return err
+	}
+	if n.expanded == nil {
+		n.expanded = make(map[string]bool)
+	}
+	n.expanded[selectedItem.Path] = true
+	n.reexpand(selectedItem.Path)
+	return nil
+}
+
+// CollapseSelected hides the contents of the selected expanded directory. On
+// an entry inside an expanded directory, it collapses that directory and
+// selects it instead.
+func (n *Navigator) CollapseSelected() {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil {
+		return
+	}
+	path := selectedItem.Path
+	if !n.isExpanded(*selectedItem) {
+		if selectedItem.Depth == 0 {
+			return
+		}
+		path = filepath.Dir(path)
+	}
+	delete(n.expanded, path)
+	n.reexpand(path)
+}
+
+// reexpand rebuilds the listing after the expansion state changed and selects
+// the item at path.
+func (n *Navigator) reexpand(path string) {
+	n.sortItems()
+	n.filterItems()
+	n.loadMetadata()
+	for i, item := range n.filteredItems {
+		if item.Path == path {
+			n.selectedIdx = i
+			return
+		}
+	}
+	n.clampSelection()
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// itemDepths returns each item's name prefixed with a dot per level of depth.
+func itemDepths(items []FileItem) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		for d := 0; d < item.Depth; d++ {
+			names[i] += "."
+		}
+		names[i] += item.Name
+	}
+	return names
+}
+
+func TestExpandSelected(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "src", "lib"), 0755)
+	os.Mkdir(filepath.Join(tempDir, "docs"), 0755)
+	for _, name := range []string{"src/main.go", "src/lib/util.go", "docs/guide.md", "readme.md"} {
+		os.WriteFile(filepath.Join(tempDir, filepath.FromSlash(name)), nil, 0644)
+	}
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "src")
+	if err := nav.ExpandSelected(); err != nil {
+		t.Fatalf("ExpandSelected failed: %v", err)
+	}
+	want := []string{"../", "docs", "src", ".lib", ".main.go", "readme.md"}
+	if got := itemDepths(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if selectedItem := nav.GetSelectedItem(); selectedItem.Name != "src" {
+		t.Errorf("Expected src to stay selected, got %s", selectedItem.Name)
+	}
+
+	// Nested and sibling expansions, kept across a rescan
+	selectByName(t, nav, "lib")
+	nav.ExpandSelected()
+	selectByName(t, nav, "docs")
+	nav.ExpandSelected()
+	nav.Refresh()
+	want = []string{"../", "docs", ".guide.md", "src", ".lib", "..util.go", ".main.go", "readme.md"}
+	if got := itemDepths(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Collapsing from inside a directory collapses it and selects it
+	selectByName(t, nav, "util.go")
+	nav.CollapseSelected()
+	if selectedItem := nav.GetSelectedItem(); selectedItem.Name != "lib" {
+		t.Errorf("Expected lib to be selected, got %s", selectedItem.Name)
+	}
+	want = []string{"../", "docs", ".guide.md", "src", ".lib", ".main.go", "readme.md"}
+	if got := itemDepths(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Collapsing src hides lib too; expanding it again brings lib back as it was
+	selectByName(t, nav, "lib")
+	nav.ExpandSelected()
+	selectByName(t, nav, "src")
+	nav.CollapseSelected()
+	want = []string{"../", "docs", ".guide.md", "src", "readme.md"}
+	if got := itemDepths(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	nav.ExpandSelected()
+	want = []string{"../", "docs", ".guide.md", "src", ".lib", "..util.go", ".main.go", "readme.md"}
+	if got := itemDepths(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Leaving the directory forgets the expansions
+	selectByName(t, nav, "docs")
+	nav.OpenSelected()
+	nav.GoUp()
+	want = []string{"../", "docs", "src", "readme.md"}
+	if got := itemDepths(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
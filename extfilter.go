@@ -0,0 +1,56 @@
+package main
+
+// ToggleExtFilter lists only the files sharing the selected file's extension,
+// such as every .go file when main.go is selected, or lists everything again
+// when the filter is already on. Directories and files without an extension
+// leave the listing alone.
+func (n *Navigator) ToggleExtFilter() {
+	selectedItem := n.GetSelectedItem()
+	if n.extFilter != "" {
+		n.setExtFilter("", selectedItem)
+		n.SetStatusMessage("Showing all entries")
+		return
+	}
+
+	if selectedItem == nil || selectedItem.IsDir || selectedItem.IsParent {
+		n.SetStatusMessage("Select a file to filter by its extension")
+		return
+	}
+	ext := extensionOf(selectedItem.Name)
+	if ext == "" {
+		n.SetStatusMessage(selectedItem.Name + " has no extension to filter by")
+		return
+	}
+	n.setExtFilter(ext, selectedItem)
+	n.SetStatusMessage("Showing only *." + ext + " files")
+}
+
+// ExtFilter returns the extension the listing is filtered to, or "".
+func (n *Navigator) ExtFilter() string {
+	return n.extFilter
+}
+
+// setExtFilter applies ext as the extension filter, keeping the selection on
+// selectedItem when it is still listed.
+func (n *Navigator) setExtFilter(ext string, selectedItem *FileItem) {
+	var selectedName string
+	if selectedItem != nil {
+		selectedName = selectedItem.Name
+	}
+	n.extFilter = ext
+	n.filterItems()
+	n.selectName(selectedName)
+}
+
+// filterByExt keeps the files in items whose extension matches the filter,
+// using the same case-insensitive comparison as the extension sort, along
+// with the parent entry so the way up stays open.
+func (n *Navigator) filterByExt(items []FileItem) []FileItem {
+	kept := []FileItem{}
+	for _, item := range items {
+		if item.IsParent || (!item.IsDir && extensionOf(item.Name) == n.extFilter) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
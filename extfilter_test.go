@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestToggleExtFilter(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"main.go", "util.GO", "readme.md", "Makefile"} {
+		os.WriteFile(filepath.Join(tempDir, name), nil, 0644)
+	}
+	os.Mkdir(filepath.Join(tempDir, "src.go"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// Directories and extensionless files leave the listing alone
+	for _, name := range []string{"src.go", "Makefile"} {
+		selectByName(t, nav, name)
+		nav.ToggleExtFilter()
+		if nav.ExtFilter() != "" || len(nav.GetItems()) != 6 {
+			t.Errorf("Expected no filter from %s, got %q with %d items", name, nav.ExtFilter(), len(nav.GetItems()))
+		}
+	}
+
+	selectByName(t, nav, "main.go")
+	nav.ToggleExtFilter()
+	if nav.ExtFilter() != "go" {
+		t.Errorf("Expected the go filter, got %q", nav.ExtFilter())
+	}
+	want := []string{"../", "main.go", "util.GO"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if selectedItem := nav.GetSelectedItem(); selectedItem == nil || selectedItem.Name != "main.go" {
+		t.Errorf("Expected main.go to stay selected, got %v", selectedItem)
+	}
+
+	// Pressing again lists everything
+	nav.ToggleExtFilter()
+	if nav.ExtFilter() != "" || len(nav.GetItems()) != 6 {
+		t.Errorf("Expected the filter cleared, got %q with %d items", nav.ExtFilter(), len(nav.GetItems()))
+	}
+}
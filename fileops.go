@@ -0,0 +1,375 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// parseMode parses an octal permission string such as "755" or "0644".
+func parseMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid mode %q: expected octal permissions like 755", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// ChmodSelected applies mode to the selected item.
+func (n *Navigator) ChmodSelected(mode os.FileMode, recursive bool) error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+	selectedItem := n.requireSelection()
+	if selectedItem == nil || selectedItem.IsParent {
+		return nil
+	}
+	if err := chmodPath(selectedItem.Path, mode, recursive, n.config.MaxDepth); err != nil {
+		return err
+	}
+	n.logOperation("chmod", chmodDetail(mode, recursive), selectedItem.Path)
+	return nil
+}
+
+// ChmodMarked applies mode to every marked item, descending into directories when
+// recursive is set. Failures are collected so one bad item does not stop the rest.
+func (n *Navigator) ChmodMarked(mode os.FileMode, recursive bool) error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+	markedItems := n.GetMarkedItems()
+	var errs []error
+	for _, item := range markedItems {
+		if err := chmodPath(item.Path, mode, recursive && item.IsDir, n.config.MaxDepth); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n.logOperation("chmod", chmodDetail(mode, recursive && item.IsDir), item.Path)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("chmod failed for %d of %d items: %w", len(errs), len(markedItems), errors.Join(errs...))
+	}
+	return nil
+}
+
+// chmodDetail describes a chmod for the operation log.
+func chmodDetail(mode os.FileMode, recursive bool) string {
+	detail := fmt.Sprintf("mode=%04o", uint32(mode))
+	if recursive {
+		detail += " recursive"
+	}
+	return detail
+}
+
+// chmodPath applies mode to path, and to everything beneath it, at most
+// maxDepth levels down, when recursive is set.
+func chmodPath(path string, mode os.FileMode, recursive bool, maxDepth int) error {
+	if !recursive {
+		return os.Chmod(path, mode)
+	}
+
+	// Collect first and apply deepest-first, so a mode without search or read
+	// permission on a directory cannot stop the walk from reaching its children
+	paths := []string{path}
+	err := walkTree(osSource{}, path, maxDepth, func(entry walkEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		paths = append(paths, entry.Path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := len(paths) - 1; i >= 0; i-- {
+		if err := os.Chmod(paths[i], mode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// splitStem splits name into its stem and extension, keeping the dot with the
+// extension. Only the last extension is split off, and as with extensionOf,
+// dotfiles such as ".bashrc" and names ending in a dot have none.
+func splitStem(name string) (stem, ext string) {
+	idx := strings.LastIndex(name, ".")
+	if idx <= 0 || idx == len(name)-1 {
+		return name, ""
+	}
+	return name[:idx], name[idx:]
+}
+
+// StartRename opens the rename prompt for the selected item with the cursor
+// before the extension. With rename_stem set, a file's prompt holds only the
+// stem and the extension is put back unless a new one is typed.
+func (n *Navigator) StartRename() {
+	if err := n.checkWritable(); err != nil {
+		n.SetStatusMessage(fmt.Sprintf("Cannot rename: %v", err))
+		return
+	}
+	selectedItem := n.requireSelection()
+	if selectedItem == nil || selectedItem.IsParent {
+		return
+	}
+	stem, ext := splitStem(selectedItem.Name)
+	if selectedItem.IsDir {
+		stem, ext = selectedItem.Name, ""
+	}
+
+	if n.config.RenameStem && ext != "" {
+		// A stem such as archive.tar has a suffix of its own, which is not a
+		// new extension when it is still there
+		_, stemExt := splitStem(stem)
+		n.StartPrompt("Rename to (keeping "+ext+"): ", stem, func(text string) error {
+			if _, typedExt := splitStem(text); typedExt == "" || typedExt == stemExt {
+				text += ext
+			}
+			return n.RenameSelected(text)
+		})
+		return
+	}
+	n.StartPrompt("Rename to: ", selectedItem.Name, n.RenameSelected)
+	n.SetPromptCursor(len([]rune(stem)))
+}
+
+// RenameSelected renames the selected item to newName in the same directory.
+// It refuses names containing a separator and never replaces an existing entry.
+func (n *Navigator) RenameSelected(newName string) error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+	selectedItem := n.requireSelection()
+	if selectedItem == nil || selectedItem.IsParent {
+		return nil
+	}
+	if err := validateName(newName); err != nil {
+		return err
+	}
+
+	oldPath := selectedItem.Path
+	newPath := filepath.Join(filepath.Dir(oldPath), newName)
+	if newPath == oldPath {
+		return nil
+	}
+	// A case-only rename on a case-insensitive filesystem finds the item itself
+	if existing, err := os.Lstat(newPath); err == nil {
+		current, err := os.Lstat(oldPath)
+		if err != nil || !os.SameFile(existing, current) {
+			return fmt.Errorf("%s already exists", newName)
+		}
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	n.logOperation("rename", "", oldPath, newPath)
+
+	if n.marked[oldPath] {
+		delete(n.marked, oldPath)
+		n.marked[newPath] = true
+	}
+	if err := n.Refresh(); err != nil {
+		return err
+	}
+	n.selectName(newName)
+	return nil
+}
+
+// CreateFile creates an empty file called name in the current directory and
+// selects it. An existing entry is never replaced.
+func (n *Navigator) CreateFile(name string) error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+	if err := validateName(name); err != nil {
+		return err
+	}
+	path := filepath.Join(n.currentPath, name)
+	if err := writeFileAtomic(path, nil, 0644); err != nil {
+		return err
+	}
+	n.logOperation("create", "", path)
+
+	if err := n.Refresh(); err != nil {
+		return err
+	}
+	n.selectName(name)
+	return nil
+}
+
+// moveTargets returns the marked items, or the selected item when nothing is marked.
+func (n *Navigator) moveTargets() []FileItem {
+	if markedItems := n.GetMarkedItems(); len(markedItems) > 0 {
+		return markedItems
+	}
+	if selectedItem := n.GetSelectedItem(); selectedItem != nil && !selectedItem.IsParent {
+		return []FileItem{*selectedItem}
+	}
+	return nil
+}
+
+// resolveDestDir returns dest as an absolute directory, relative to the
+// current directory, or an error when it is not a directory. Every move
+// starts here, so it also refuses moves from a remote directory.
+func (n *Navigator) resolveDestDir(dest string) (string, error) {
+	if err := n.checkWritable(); err != nil {
+		return "", err
+	}
+	dest = normalizePath(dest, n.currentPath)
+	info, err := os.Stat(dest)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dest)
+	}
+	return dest, nil
+}
+
+// MoveNeedsCopy reports how many of the items to move would cross to another
+// filesystem, where they are copied and then removed instead of renamed.
+func (n *Navigator) MoveNeedsCopy(dest string) (int, error) {
+	destDir, err := n.resolveDestDir(dest)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, item := range n.moveTargets() {
+		if crossesDevice(item.Path, destDir) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MoveItems moves the marked items, or the selected item, into dest. An
+// existing entry of the same name is skipped, replaced, kept beside a renamed
+// copy or refused as choices, keyed by name, says, or else as on_collision
+// does. Failures are collected so one bad item does not stop the rest.
+func (n *Navigator) MoveItems(dest string, choices map[string]CollisionPolicy) error {
+	destDir, err := n.resolveDestDir(dest)
+	if err != nil {
+		return err
+	}
+
+	targets := n.moveTargets()
+	moved := make(map[string]bool)
+	var errs []error
+	for _, item := range targets {
+		name := filepath.Base(item.Path)
+		newPath := filepath.Join(destDir, name)
+		if newPath == item.Path {
+			continue
+		}
+		move := func(src, dst string) error {
+			return movePath(src, dst, n.config.MaxDepth, n.config.PreserveAttributes, n.config.OneFileSystem)
+		}
+		if _, err := os.Lstat(newPath); err == nil {
+			switch n.collisionPolicy(name, choices) {
+			case CollisionSkip:
+				continue
+			case CollisionRename:
+				newPath = freeName(destDir, name)
+			case CollisionOverwrite:
+				move = n.moveReplacing
+			default:
+				errs = append(errs, fmt.Errorf("%s already exists in %s", name, destDir))
+				continue
+			}
+		}
+		if err := move(item.Path, newPath); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		delete(n.marked, item.Path)
+		moved[item.Path] = true
+		n.logOperation("move", "", item.Path, newPath)
+	}
+
+	n.dropItems(moved)
+	if err := n.Refresh(); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("move failed for %d of %d items: %w", len(errs), len(targets), errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateName checks that name can be used as a single directory entry.
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		return fmt.Errorf("invalid name %q", name)
+	}
+	return nil
+}
+
+// renameFile moves files into place; tests replace it to simulate failures.
+var renameFile = os.Rename
+
+// writeFileAtomic writes data to path as replaceFileAtomic does, but fails
+// with an error wrapping fs.ErrExist rather than replace an existing entry.
+// The finished file is hard-linked into place, which unlike a rename refuses
+// an entry that appeared while it was being written.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tempPath, err := writeTempFile(path, data, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempPath)
+	if err := os.Link(tempPath, path); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%s: %w", filepath.Base(path), fs.ErrExist)
+		}
+		return err
+	}
+	return nil
+}
+
+// replaceFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a crash never leaves a half-written file at path and
+// concurrent writers each replace it whole.
+func replaceFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tempPath, err := writeTempFile(path, data, perm)
+	if err != nil {
+		return err
+	}
+	if err := renameFile(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+// writeTempFile writes data with mode perm to a new hidden file next to path
+// and returns its name. Nothing is left behind when it fails.
+func writeTempFile(path string, data []byte, perm os.FileMode) (string, error) {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tempPath := temp.Name()
+
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, perm)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return "", err
+	}
+	return tempPath, nil
+}
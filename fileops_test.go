@@ -0,0 +1,329 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	valid := map[string]os.FileMode{"755": 0755, "0644": 0644, "600": 0600, "0": 0}
+	for input, want := range valid {
+		got, err := parseMode(input)
+		if err != nil || got != want {
+			t.Errorf("parseMode(%q) = %o, %v; want %o", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "rwx", "789", "1777", "-1"} {
+		if _, err := parseMode(input); err == nil {
+			t.Errorf("parseMode(%q) expected an error", input)
+		}
+	}
+}
+
+func TestChmodMarked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("content"), 0644)
+	}
+	os.Mkdir(filepath.Join(tempDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "sub", "inner.txt"), []byte("content"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	for _, name := range []string{"a.txt", "b.txt", "sub"} {
+		selectByName(t, nav, name)
+		nav.ToggleMark()
+	}
+
+	if err := nav.ChmodMarked(0600, false); err != nil {
+		t.Fatalf("ChmodMarked failed: %v", err)
+	}
+	assertMode(t, filepath.Join(tempDir, "a.txt"), 0600)
+	assertMode(t, filepath.Join(tempDir, "b.txt"), 0600)
+	assertMode(t, filepath.Join(tempDir, "c.txt"), 0644) // Not marked
+	assertMode(t, filepath.Join(tempDir, "sub", "inner.txt"), 0644)
+
+	// Recursive chmod reaches into marked directories, even when the new mode
+	// removes the directory's own search permission
+	if err := nav.ChmodMarked(0600, true); err != nil {
+		t.Fatalf("recursive ChmodMarked failed: %v", err)
+	}
+	assertMode(t, filepath.Join(tempDir, "sub"), 0600)
+	os.Chmod(filepath.Join(tempDir, "sub"), 0755)
+	assertMode(t, filepath.Join(tempDir, "sub", "inner.txt"), 0600)
+}
+
+func TestChmodMarkedAggregatesErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("content"), 0644)
+	}
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		selectByName(t, nav, name)
+		nav.ToggleMark()
+	}
+
+	// Remove two marked files so their chmod fails
+	os.Remove(filepath.Join(tempDir, "a.txt"))
+	os.Remove(filepath.Join(tempDir, "c.txt"))
+
+	err := nav.ChmodMarked(0600, false)
+	if err == nil {
+		t.Fatal("ChmodMarked expected an error for missing files")
+	}
+	if !strings.Contains(err.Error(), "2 of 3") {
+		t.Errorf("ChmodMarked error should summarize failures, got %q", err)
+	}
+	if !strings.Contains(err.Error(), "a.txt") || !strings.Contains(err.Error(), "c.txt") {
+		t.Errorf("ChmodMarked error should list each failure, got %q", err)
+	}
+	assertMode(t, filepath.Join(tempDir, "b.txt"), 0600)
+}
+
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Errorf("Stat %s failed: %v", path, err)
+		return
+	}
+	if got := info.Mode().Perm(); got != want {
+		t.Errorf("Expected mode %o for %s, got %o", want, path, got)
+	}
+}
+
+func TestRenameSelected(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "file1.txt")
+	nav.ToggleMark()
+
+	if err := nav.RenameSelected("notes.txt"); err != nil {
+		t.Fatalf("RenameSelected failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "notes.txt")); err != nil {
+		t.Errorf("Expected notes.txt to exist: %v", err)
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "notes.txt" {
+		t.Errorf("Expected notes.txt selected, got %v", selected)
+	}
+	if !nav.IsMarked(filepath.Join(tempDir, "notes.txt")) {
+		t.Error("Expected the mark to follow the renamed item")
+	}
+
+	for _, name := range []string{"", "..", "a/b", "dir1"} {
+		if err := nav.RenameSelected(name); err == nil {
+			t.Errorf("RenameSelected(%q) expected an error", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "notes.txt")); err != nil {
+		t.Errorf("Expected notes.txt untouched after rejected renames: %v", err)
+	}
+}
+
+func TestSplitStem(t *testing.T) {
+	tests := []struct {
+		name, stem, ext string
+	}{
+		{"notes.txt", "notes", ".txt"},
+		{"archive.tar.gz", "archive.tar", ".gz"},
+		{"v1.2.3.zip", "v1.2.3", ".zip"},
+		{".bashrc", ".bashrc", ""},
+		{".config.json", ".config", ".json"},
+		{"Makefile", "Makefile", ""},
+		{"trailing.", "trailing.", ""},
+	}
+	for _, tt := range tests {
+		if stem, ext := splitStem(tt.name); stem != tt.stem || ext != tt.ext {
+			t.Errorf("splitStem(%q) = %q, %q, want %q, %q", tt.name, stem, ext, tt.stem, tt.ext)
+		}
+	}
+}
+
+func TestStartRename(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "file1.txt")
+
+	// The cursor starts before the extension, so typing edits the stem
+	nav.StartRename()
+	nav.InsertPromptText("-old")
+	if prompt := nav.GetPrompt(); prompt == nil || prompt.Text != "file1-old.txt" {
+		t.Fatalf("Expected typing before the extension, got %+v", prompt)
+	}
+	nav.DeletePromptBackward()
+	nav.SetPromptCursor(100)
+	nav.InsertPromptText("!")
+	if prompt := nav.GetPrompt(); prompt.Text != "file1-ol.txt!" {
+		t.Errorf("Expected editing at the cursor, got %q", prompt.Text)
+	}
+	nav.CancelPrompt()
+
+	// In stem mode the extension comes back unless a new one is typed
+	cfg := nav.GetConfig()
+	cfg.RenameStem = true
+	nav.SetConfig(cfg)
+	nav.StartRename()
+	if prompt := nav.GetPrompt(); prompt == nil || prompt.Text != "file1" {
+		t.Fatalf("Expected only the stem in the prompt, got %+v", prompt)
+	}
+	nav.SetPromptText("notes")
+	if err := nav.SubmitPrompt(); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "notes.txt")); err != nil {
+		t.Errorf("Expected the extension kept: %v", err)
+	}
+
+	nav.StartRename()
+	nav.SetPromptText("notes.md")
+	nav.SubmitPrompt()
+	if _, err := os.Stat(filepath.Join(tempDir, "notes.md")); err != nil {
+		t.Errorf("Expected a typed extension to replace the old one: %v", err)
+	}
+
+	// Dots inside the stem are not mistaken for a typed extension
+	tests := []struct {
+		name, stem, typed, want string
+	}{
+		{"archive.tar.gz", "archive.tar", "archive.tar", "archive.tar.gz"},
+		{"archive.tar.gz", "archive.tar", "backup.tar", "backup.tar.gz"},
+		{"archive.tar.gz", "archive.tar", "backup.tgz", "backup.tgz"},
+		{"v1.2.3.zip", "v1.2.3", "v1.2.3", "v1.2.3.zip"},
+		{"v1.2.3.zip", "v1.2.3", "release", "release.zip"},
+	}
+	for _, test := range tests {
+		os.WriteFile(filepath.Join(tempDir, test.name), nil, 0644)
+		nav.Refresh()
+		selectByName(t, nav, test.name)
+		nav.StartRename()
+		if prompt := nav.GetPrompt(); prompt == nil || prompt.Text != test.stem {
+			t.Fatalf("Expected the stem %q for %s, got %+v", test.stem, test.name, prompt)
+		}
+		nav.SetPromptText(test.typed)
+		if err := nav.SubmitPrompt(); err != nil {
+			t.Fatalf("Renaming %s to %q failed: %v", test.name, test.typed, err)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, test.want)); err != nil {
+			t.Errorf("Expected %s typed as %q to become %s: %v", test.name, test.typed, test.want, err)
+		}
+		os.Remove(filepath.Join(tempDir, test.want))
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "new.txt")
+
+	if err := writeFileAtomic(path, []byte("hello"), 0600); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello" {
+		t.Errorf("Expected hello, got %q", data)
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+			t.Errorf("Expected mode 0600, got %o", info.Mode().Perm())
+		}
+	}
+
+	// An existing file is never replaced
+	err := writeFileAtomic(path, []byte("other"), 0644)
+	if !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Expected fs.ErrExist for an existing file, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello" {
+		t.Errorf("Expected the existing file untouched, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected the temp file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestReplaceFileAtomicCleansUpOnError(t *testing.T) {
+	dir := t.TempDir()
+	renameFile = func(oldpath, newpath string) error {
+		return errors.New("simulated crash")
+	}
+	defer func() { renameFile = os.Rename }()
+
+	if err := replaceFileAtomic(filepath.Join(dir, "new.txt"), []byte("x"), 0644); err == nil {
+		t.Fatal("Expected the simulated rename failure to be returned")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the temp file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestCreateFile(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	if err := nav.CreateFile("todo.md"); err != nil {
+		t.Fatalf("CreateFile failed: %v", err)
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "todo.md" {
+		t.Errorf("Expected todo.md selected, got %v", selected)
+	}
+	if err := nav.CreateFile("file1.txt"); err == nil {
+		t.Error("Expected CreateFile to refuse an existing name")
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "file1.txt")); string(data) != "content" {
+		t.Errorf("Expected file1.txt untouched, got %q", data)
+	}
+}
+
+func TestRemoveLastItemClampsSelection(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("content"), 0644)
+	}
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "c.txt")
+	last := nav.GetSelectedIndex()
+
+	// Check the listing right after the item is dropped, before any rescan
+	nav.dropItems(map[string]bool{filepath.Join(tempDir, "c.txt"): true})
+	if nav.GetSelectedIndex() != last-1 || nav.GetSelectedItem() == nil {
+		t.Fatalf("Expected the selection to clamp to index %d, got %d", last-1, nav.GetSelectedIndex())
+	}
+
+	// Moving it away for real keeps the selection at the end rather than the top
+	os.Mkdir(filepath.Join(tempDir, "a_dir"), 0755)
+	nav.Refresh()
+	selectByName(t, nav, "c.txt")
+	if err := nav.MoveItems("a_dir", nil); err != nil {
+		t.Fatalf("MoveItems failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "c.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected c.txt to be moved, got %v", err)
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "b.txt" {
+		t.Errorf("Expected the selection to clamp to the new last item b.txt, got %v", selected)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// FileType classifies a directory entry by its mode type bits.
+type FileType int
+
+const (
+	TypeRegular FileType = iota
+	TypeDir
+	TypeSymlink
+	TypeNamedPipe
+	TypeSocket
+	TypeDevice
+	TypeOther
+)
+
+// fileTypeOf returns the category of an entry with the given mode.
+func fileTypeOf(mode fs.FileMode) FileType {
+	switch {
+	case mode.IsRegular():
+		return TypeRegular
+	case mode.IsDir():
+		return TypeDir
+	case mode&fs.ModeSymlink != 0:
+		return TypeSymlink
+	case mode&fs.ModeNamedPipe != 0:
+		return TypeNamedPipe
+	case mode&fs.ModeSocket != 0:
+		return TypeSocket
+	case mode&fs.ModeDevice != 0:
+		return TypeDevice
+	default:
+		return TypeOther
+	}
+}
+
+// String returns a human-readable name for the file type.
+func (t FileType) String() string {
+	switch t {
+	case TypeRegular:
+		return "file"
+	case TypeDir:
+		return "directory"
+	case TypeSymlink:
+		return "symlink"
+	case TypeNamedPipe:
+		return "named pipe"
+	case TypeSocket:
+		return "socket"
+	case TypeDevice:
+		return "device"
+	default:
+		return "special file"
+	}
+}
+
+// Indicator returns the suffix drawn after special entries, in the style of ls -F.
+func (t FileType) Indicator() string {
+	switch t {
+	case TypeSymlink:
+		return "@"
+	case TypeNamedPipe:
+		return "|"
+	case TypeSocket:
+		return "="
+	case TypeDevice:
+		return "#"
+	default:
+		return ""
+	}
+}
+
+// requireRegular returns an error unless mode describes a regular file, so
+// reads never block on a FIFO or device.
+func requireRegular(name string, mode fs.FileMode) error {
+	if t := fileTypeOf(mode); t != TypeRegular {
+		return fmt.Errorf("%s is a %s, not a regular file", name, t)
+	}
+	return nil
+}
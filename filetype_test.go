@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestFileTypeOf(t *testing.T) {
+	tests := []struct {
+		mode      fs.FileMode
+		want      FileType
+		indicator string
+	}{
+		{0644, TypeRegular, ""},
+		{fs.ModeDir | 0755, TypeDir, ""},
+		{fs.ModeSymlink | 0777, TypeSymlink, "@"},
+		{fs.ModeNamedPipe | 0644, TypeNamedPipe, "|"},
+		{fs.ModeSocket | 0755, TypeSocket, "="},
+		{fs.ModeDevice | 0660, TypeDevice, "#"},
+		{fs.ModeDevice | fs.ModeCharDevice | 0660, TypeDevice, "#"},
+		{fs.ModeIrregular, TypeOther, ""},
+	}
+	for _, tt := range tests {
+		got := fileTypeOf(tt.mode)
+		if got != tt.want {
+			t.Errorf("fileTypeOf(%v) = %v, want %v", tt.mode, got, tt.want)
+		}
+		if got.Indicator() != tt.indicator {
+			t.Errorf("%v indicator = %q, want %q", got, got.Indicator(), tt.indicator)
+		}
+	}
+}
+
+func TestRequireRegular(t *testing.T) {
+	if err := requireRegular("a.txt", 0644); err != nil {
+		t.Errorf("requireRegular refused a regular file: %v", err)
+	}
+	if err := requireRegular("pipe", fs.ModeNamedPipe|0644); err == nil {
+		t.Error("requireRegular accepted a named pipe")
+	}
+}
@@ -0,0 +1,47 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestScanDetectsNamedPipe(t *testing.T) {
+	tempDir := t.TempDir()
+	fifoPath := filepath.Join(tempDir, "pipe")
+	if err := syscall.Mkfifo(fifoPath, 0644); err != nil {
+		t.Skipf("Cannot create FIFO: %v", err)
+	}
+	os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("content"), 0644)
+	os.Symlink(fifoPath, filepath.Join(tempDir, "link-to-pipe"))
+
+	nav, _ := NewNavigator(tempDir)
+	if err := nav.ScanDirectory(); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	selectByName(t, nav, "pipe")
+	if nav.GetSelectedItem().Type != TypeNamedPipe {
+		t.Errorf("Expected pipe to be a named pipe, got %v", nav.GetSelectedItem().Type)
+	}
+	// Previewing must refuse rather than block on the FIFO
+	if _, err := nav.PreviewSelected(); err == nil {
+		t.Error("PreviewSelected should refuse a named pipe")
+	}
+
+	selectByName(t, nav, "link-to-pipe")
+	if nav.GetSelectedItem().Type != TypeSymlink {
+		t.Errorf("Expected link-to-pipe to be a symlink, got %v", nav.GetSelectedItem().Type)
+	}
+	if _, err := nav.PreviewSelected(); err == nil {
+		t.Error("PreviewSelected should refuse a symlink to a named pipe")
+	}
+
+	selectByName(t, nav, "file.txt")
+	if nav.GetSelectedItem().Type != TypeRegular {
+		t.Errorf("Expected file.txt to be a regular file, got %v", nav.GetSelectedItem().Type)
+	}
+}
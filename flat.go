@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+)
+
+// maxFlatItems bounds how many files the flat view collects.
+const maxFlatItems = 10000
+
+// ToggleFlatView switches between the normal listing and a flat list of every
+// file beneath the current directory, named by relative path.
+func (n *Navigator) ToggleFlatView() error {
+	n.flatView = !n.flatView
+	n.selectedIdx = 0
+	n.scrollOffset = 0
+	return n.ScanDirectory()
+}
+
+// IsFlatView reports whether the flat recursive view is active, and whether it
+// was cut short at maxFlatItems.
+func (n *Navigator) IsFlatView() (flat, truncated bool) {
+	return n.flatView, n.flatTruncated
+}
+
+// flatItems walks root through source and returns up to limit files, each
+// named by its path relative to root. Unreadable directories are skipped and
+// symlinked directories are not followed.
+func flatItems(source FileSource, root string, limit int) (items []FileItem, truncated bool) {
+	var walk func(dir string) bool
+	walk = func(dir string) bool {
+		entries, err := source.ReadDir(dir)
+		if err != nil {
+			return true
+		}
+		for _, entry := range entries {
+			if entry.IsDir {
+				if !walk(entry.Path) {
+					return false
+				}
+				continue
+			}
+			if len(items) >= limit {
+				return false
+			}
+			if rel, err := filepath.Rel(root, entry.Path); err == nil {
+				entry.Name = rel
+				items = append(items, entry)
+			}
+		}
+		return true
+	}
+	return items, !walk(root)
+}
+
+// revealFlatItem leaves the flat view and selects item in its own directory.
+func (n *Navigator) revealFlatItem(item FileItem) error {
+	if err := n.changeDirectory(filepath.Dir(item.Path)); err != nil {
+		return err
+	}
+	n.selectName(filepath.Base(item.Path))
+	return nil
+}
+
+// openFlatItem runs the enter rule for a flat view result without leaving the
+// flat view. Rules match the file's own name, not the relative path it is
+// listed under.
+func (n *Navigator) openFlatItem(item FileItem) error {
+	item.Name = filepath.Base(item.Path)
+	return n.openFile(item)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFlatView(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "src", "pkg"), 0755)
+	os.Mkdir(filepath.Join(tempDir, "empty"), 0755)
+	for _, name := range []string{"top.txt", filepath.Join("src", "main.go"), filepath.Join("src", "pkg", "util.go")} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("x"), 0644)
+	}
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	if err := nav.ToggleFlatView(); err != nil {
+		t.Fatalf("ToggleFlatView failed: %v", err)
+	}
+
+	// Only files are listed, by relative path, after the parent entry
+	want := []string{"../", filepath.Join("src", "main.go"), filepath.Join("src", "pkg", "util.go"), "top.txt"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected flat items %v, got %v", want, got)
+	}
+
+	// Filtering works across the whole subtree
+	nav.ToggleSearchMode()
+	nav.SetSearchTerm("util")
+	if items := nav.GetItems(); len(items) != 1 || items[0].Name != filepath.Join("src", "pkg", "util.go") {
+		t.Errorf("Expected util.go to match, got %v", itemNames(items))
+	}
+	nav.ToggleSearchMode()
+
+	// Opening a file reveals it in its own directory
+	selectByName(t, nav, filepath.Join("src", "pkg", "util.go"))
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if want := filepath.Join(tempDir, "src", "pkg"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected to navigate to %q, got %q", want, nav.GetCurrentPath())
+	}
+	if flat, _ := nav.IsFlatView(); flat {
+		t.Error("Expected the flat view to end after revealing a file")
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "util.go" {
+		t.Errorf("Expected util.go selected, got %v", selected)
+	}
+}
+
+func TestFlatItemsLimit(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("x"), 0644)
+	}
+
+	items, truncated := flatItems(osSource{}, tempDir, 2)
+	if len(items) != 2 || !truncated {
+		t.Errorf("Expected 2 items and truncation, got %d (truncated %v)", len(items), truncated)
+	}
+	if _, truncated := flatItems(osSource{}, tempDir, 3); truncated {
+		t.Error("Expected no truncation when the limit is not exceeded")
+	}
+}
+
+func TestFlatResultNavigation(t *testing.T) {
+	t.Setenv("VISUAL", "fake-editor")
+	tempDir := t.TempDir()
+	deep := filepath.Join(tempDir, "a", "b", "c")
+	os.MkdirAll(deep, 0755)
+	os.WriteFile(filepath.Join(deep, "notes.md"), []byte("# notes"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "a", "notes.md"), []byte("# other"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.EnterRules = "md:edit"
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	nav.ToggleFlatView()
+
+	var edited []string
+	nav.SetForegroundRunner(func(cmd *exec.Cmd) error {
+		edited = append(edited, cmd.Args[len(cmd.Args)-1])
+		return nil
+	})
+
+	// Alt-Enter opens the result itself, matching rules against its own name
+	rel := filepath.Join("a", "b", "c", "notes.md")
+	selectByName(t, nav, rel)
+	if err := nav.EnterSelected(); err != nil {
+		t.Fatalf("EnterSelected failed: %v", err)
+	}
+	if len(edited) != 1 || edited[0] != filepath.Join(deep, "notes.md") {
+		t.Errorf("Expected the deep notes.md in the editor, got %v", edited)
+	}
+	if flat, _ := nav.IsFlatView(); !flat || nav.GetCurrentPath() != tempDir {
+		t.Error("Expected to stay in the flat view")
+	}
+
+	// Enter lands in the file's directory with the file selected
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if nav.GetCurrentPath() != deep {
+		t.Errorf("Expected to navigate to %q, got %q", deep, nav.GetCurrentPath())
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Path != filepath.Join(deep, "notes.md") {
+		t.Errorf("Expected the deep notes.md selected, got %v", selected)
+	}
+}
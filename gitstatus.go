@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GitStatus is an entry's state in its git repository. Higher values are
+// more important, and a directory shows the most important status of
+// anything below it.
+type GitStatus int
+
+const (
+	GitClean GitStatus = iota
+	GitUntracked
+	GitAdded
+	GitRenamed
+	GitDeleted
+	GitModified
+	GitConflict
+)
+
+// Indicator returns the letter shown before an entry with the status, or a
+// space when it is clean.
+func (s GitStatus) Indicator() string {
+	switch s {
+	case GitUntracked:
+		return "?"
+	case GitAdded:
+		return "A"
+	case GitRenamed:
+		return "R"
+	case GitDeleted:
+		return "D"
+	case GitModified:
+		return "M"
+	case GitConflict:
+		return "U"
+	}
+	return " "
+}
+
+// gitTimeout bounds each git command, so a huge or stuck repository cannot
+// keep a load running forever.
+const gitTimeout = 10 * time.Second
+
+// gitLoadExpiry is how long a background load may go undelivered before it
+// is taken as lost and another may start. gitStatusesIn runs git twice, each
+// bounded by gitTimeout, so a load still running is never given up on.
+const gitLoadExpiry = 2*gitTimeout + time.Second
+
+// gitOutput runs git; tests replace it to stand in for git.
+var gitOutput = defaultGitOutput
+
+// defaultGitOutput runs git with args in dir and returns its output. The
+// repositories browsed may not be trusted, so core.fsmonitor, which names a
+// command for git to run, is turned off, and GIT_OPTIONAL_LOCKS=0 keeps git
+// from taking index.lock to refresh the index behind the user's back.
+func defaultGitOutput(dir string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "core.fsmonitor=false"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	return cmd.Output()
+}
+
+// statusFromXY classifies the two-letter XY code of a porcelain status line.
+func statusFromXY(xy string) GitStatus {
+	x, y := xy[0], xy[1]
+	switch {
+	case xy == "??":
+		return GitUntracked
+	case x == 'U' || y == 'U' || xy == "AA" || xy == "DD":
+		return GitConflict
+	case x == 'A':
+		return GitAdded
+	case x == 'R' || x == 'C':
+		return GitRenamed
+	case x == 'D' || y == 'D':
+		return GitDeleted
+	}
+	return GitModified
+}
+
+// parsePorcelain parses the output of git status --porcelain -z into statuses
+// by path, relative to the repository root with forward slashes. Untracked
+// directories git collapses keep their trailing slash; the entries ignored
+// with !! are left out.
+func parsePorcelain(output []byte) map[string]GitStatus {
+	statuses := make(map[string]GitStatus)
+	fields := bytes.Split(output, []byte{0})
+	for i := 0; i < len(fields); i++ {
+		line := string(fields[i])
+		if len(line) < 4 || line[2] != ' ' {
+			continue
+		}
+		xy, path := line[:2], line[3:]
+		if xy[0] == 'R' || xy[0] == 'C' {
+			i++ // The original path of a rename or copy follows
+		}
+		if xy == "!!" {
+			continue
+		}
+		statuses[path] = statusFromXY(xy)
+	}
+	return statuses
+}
+
+// gitStatusesIn returns the git status of the entries in and below dir, by
+// absolute path, with every directory showing the most important status
+// inside it. It returns nil when git is missing or dir is not in a work tree.
+func gitStatusesIn(dir string) map[string]GitStatus {
+	// The prefix is dir relative to the repository root, e.g. "src/pkg/"
+	prefix, err := gitOutput(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil
+	}
+	// Untracked files are listed one by one, so those inside an untracked
+	// current directory are marked too
+	output, err := gitOutput(dir, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil
+	}
+
+	statuses := make(map[string]GitStatus)
+	dirPrefix := strings.TrimSpace(string(prefix))
+	for rel, status := range parsePorcelain(output) {
+		rel, ok := strings.CutPrefix(rel, dirPrefix)
+		if !ok || rel == "" {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		for ; path != dir && len(path) > len(dir); path = filepath.Dir(path) {
+			if status > statuses[path] {
+				statuses[path] = status
+			}
+		}
+	}
+	return statuses
+}
+
+// loadGitStatus fetches the git status of the current directory when
+// show_git is set, once per directory and again after each refresh. Git is a
+// program, so nothing runs with --no-exec, and remote directories have no
+// local work tree to ask about.
+//
+// With SetAsync, git runs in the background and the status column shows up
+// once its result is delivered, so a large repository never stalls the UI.
+// After a refresh the previous status stays shown until the new one arrives,
+// and only one load runs per directory: a refresh while one runs starts the
+// next once it is delivered.
+func (n *Navigator) loadGitStatus() {
+	if !n.config.ShowGit || n.config.NoExec || n.isRemote() {
+		return
+	}
+	dir := n.currentPath
+	if _, ok := n.gitCache[dir]; ok && !n.gitStale[dir] {
+		return
+	}
+	if n.deliver == nil {
+		delete(n.gitStale, dir)
+		n.setGitStatus(dir, gitStatusesIn(dir))
+		return
+	}
+	// A load whose result never arrived, say because the event queue was
+	// full, is given up on
+	if load, loading := n.gitLoads[dir]; loading && time.Since(load.started) < gitLoadExpiry {
+		return
+	}
+
+	if n.gitLoads == nil {
+		n.gitLoads = make(map[string]gitLoad)
+	}
+	n.gitLoadCount++
+	token := n.gitLoadCount
+	n.gitLoads[dir] = gitLoad{token: token, started: time.Now()}
+	delete(n.gitStale, dir)
+	deliver := n.deliver
+	go func() {
+		statuses := gitStatusesIn(dir)
+		deliver(func() {
+			// A load given up on was replaced by a newer one
+			if n.gitLoads[dir].token != token {
+				return
+			}
+			delete(n.gitLoads, dir)
+			n.setGitStatus(dir, statuses)
+			if n.gitStale[dir] && dir == n.currentPath {
+				n.loadGitStatus()
+			}
+		})
+	}()
+}
+
+// gitLoad is a git status load running in the background.
+type gitLoad struct {
+	token   int // Tells the load apart from a later one for the same directory
+	started time.Time
+}
+
+// setGitStatus caches the git status of dir.
+func (n *Navigator) setGitStatus(dir string, statuses map[string]GitStatus) {
+	if n.gitCache == nil {
+		n.gitCache = make(map[string]map[string]GitStatus)
+	}
+	n.gitCache[dir] = statuses
+}
+
+// markGitStale has the next scan of dir ask git again, keeping the cached
+// status shown meanwhile.
+func (n *Navigator) markGitStale(dir string) {
+	if n.gitStale == nil {
+		n.gitStale = make(map[string]bool)
+	}
+	n.gitStale[dir] = true
+}
+
+// SetAsync makes slow lookups such as the git status run in the background.
+// deliver must call apply on the goroutine driving the Navigator, for
+// example by posting an event to the main loop.
+func (n *Navigator) SetAsync(deliver func(apply func())) {
+	n.deliver = deliver
+}
+
+// InGitRepo reports whether show_git is set and the current directory is in
+// a git work tree, so the status column is shown.
+func (n *Navigator) InGitRepo() bool {
+	return n.config.ShowGit && n.gitCache[n.currentPath] != nil
+}
+
+// GitStatusOf returns item's git status, GitClean when it has none.
+func (n *Navigator) GitStatusOf(item FileItem) GitStatus {
+	return n.gitCache[n.currentPath][item.Path]
+}
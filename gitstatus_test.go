@@ -0,0 +1,233 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParsePorcelain(t *testing.T) {
+	output := strings.Join([]string{
+		" M main.go",
+		"M  src/util.go",
+		"A  src/new.go",
+		"R  docs/guide.md",
+		"docs/old guide.md", // The original path of the rename
+		"?? notes.txt",
+		"?? build/",
+		" D gone.txt",
+		"UU merge.go",
+		"!! vendor/",
+		"",
+	}, "\x00")
+
+	want := map[string]GitStatus{
+		"main.go":       GitModified,
+		"src/util.go":   GitModified,
+		"src/new.go":    GitAdded,
+		"docs/guide.md": GitRenamed,
+		"notes.txt":     GitUntracked,
+		"build/":        GitUntracked,
+		"gone.txt":      GitDeleted,
+		"merge.go":      GitConflict,
+	}
+	if got := parsePorcelain([]byte(output)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePorcelain =\n%v\nwant\n%v", got, want)
+	}
+	if got := parsePorcelain(nil); len(got) != 0 {
+		t.Errorf("Expected a clean tree to have no statuses, got %v", got)
+	}
+}
+
+func TestGitStatusesIn(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, "app")
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+
+	gitOutput = func(_ string, args ...string) ([]byte, error) {
+		if args[0] == "rev-parse" {
+			return []byte("app/\n"), nil
+		}
+		return []byte("?? app/src/new.go\x00 M app/src/util.go\x00 M app/main.go\x00 M other/file.go\x00"), nil
+	}
+	defer func() { gitOutput = defaultGitOutput }()
+
+	want := map[string]GitStatus{
+		filepath.Join(dir, "src", "new.go"):  GitUntracked,
+		filepath.Join(dir, "src", "util.go"): GitModified,
+		filepath.Join(dir, "src"):            GitModified, // The most important status inside
+		filepath.Join(dir, "main.go"):        GitModified,
+	}
+	if got := gitStatusesIn(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("gitStatusesIn =\n%v\nwant\n%v", got, want)
+	}
+
+	// Outside a repository, or without git, nothing is shown
+	gitOutput = func(string, ...string) ([]byte, error) {
+		return nil, os.ErrNotExist
+	}
+	nav, _ := NewNavigator(dir)
+	cfg := nav.GetConfig()
+	cfg.ShowGit = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	if nav.InGitRepo() {
+		t.Error("Expected no git column when git fails")
+	}
+}
+
+func TestGitStatusCachedPerDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), nil, 0644)
+
+	runs := 0
+	gitOutput = func(_ string, args ...string) ([]byte, error) {
+		runs++
+		if args[0] == "rev-parse" {
+			return []byte("\n"), nil
+		}
+		return []byte(" M main.go\x00"), nil
+	}
+	defer func() { gitOutput = defaultGitOutput }()
+
+	nav, _ := NewNavigator(dir)
+	cfg := nav.GetConfig()
+	cfg.ShowGit = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	nav.ScanDirectory()
+	if runs != 2 {
+		t.Errorf("Expected git to run once per directory, got %d commands", runs)
+	}
+	selectByName(t, nav, "main.go")
+	if !nav.InGitRepo() || nav.GitStatusOf(*nav.GetSelectedItem()) != GitModified {
+		t.Errorf("Expected main.go to show as modified")
+	}
+
+	// A refresh asks git again
+	nav.Refresh()
+	if runs != 4 {
+		t.Errorf("Expected a refresh to run git again, got %d commands", runs)
+	}
+}
+
+func TestGitStatusNoExec(t *testing.T) {
+	dir := t.TempDir()
+	runs := 0
+	gitOutput = func(string, ...string) ([]byte, error) {
+		runs++
+		return nil, nil
+	}
+	defer func() { gitOutput = defaultGitOutput }()
+
+	nav, _ := NewNavigator(dir)
+	cfg := nav.GetConfig()
+	cfg.ShowGit, cfg.NoExec = true, true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	if runs != 0 || nav.InGitRepo() {
+		t.Errorf("Expected git not to run with --no-exec, got %d commands", runs)
+	}
+}
+
+func TestGitStatusAsync(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), nil, 0644)
+	var status atomic.Value
+	status.Store(" M main.go\x00")
+	gitOutput = func(_ string, args ...string) ([]byte, error) {
+		if args[0] == "rev-parse" {
+			return []byte("\n"), nil
+		}
+		return []byte(status.Load().(string)), nil
+	}
+	defer func() { gitOutput = defaultGitOutput }()
+
+	results := make(chan func(), 2)
+	nav, _ := NewNavigator(dir)
+	nav.SetAsync(func(apply func()) { results <- apply })
+	cfg := nav.GetConfig()
+	cfg.ShowGit = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	nav.ScanDirectory()
+	if nav.InGitRepo() {
+		t.Fatal("Expected no status before the background load is delivered")
+	}
+
+	receive := func() func() {
+		select {
+		case apply := <-results:
+			return apply
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the git status to be delivered")
+			return nil
+		}
+	}
+	expectNoLoad := func(when string) {
+		select {
+		case <-results:
+			t.Errorf("Expected no second load %s", when)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	first := receive()
+	expectNoLoad("while one runs")
+
+	// A refresh while the load runs waits for it, then loads again
+	nav.Refresh()
+	expectNoLoad("after a refresh while one runs")
+	status.Store("A  main.go\x00")
+	first()
+	selectByName(t, nav, "main.go")
+	if !nav.InGitRepo() || nav.GitStatusOf(*nav.GetSelectedItem()) != GitModified {
+		t.Error("Expected main.go to show as modified once delivered")
+	}
+
+	// The old status stays shown until the new one is delivered
+	second := receive()
+	if nav.GitStatusOf(*nav.GetSelectedItem()) != GitModified {
+		t.Error("Expected the previous status to stay shown while reloading")
+	}
+	second()
+	if nav.GitStatusOf(*nav.GetSelectedItem()) != GitAdded {
+		t.Errorf("Expected the reloaded status, got %v", nav.GitStatusOf(*nav.GetSelectedItem()))
+	}
+	expectNoLoad("once the status is current")
+
+	// A load whose result was lost is given up on after a while
+	nav.Refresh()
+	lost := receive()
+	nav.gitLoads[dir] = gitLoad{token: nav.gitLoads[dir].token, started: time.Now().Add(-gitLoadExpiry)}
+	nav.Refresh()
+	replacement := receive()
+	lost()
+	if _, loading := nav.gitLoads[dir]; !loading {
+		t.Error("Expected the late result of a lost load to be ignored")
+	}
+	replacement()
+	if _, loading := nav.gitLoads[dir]; loading {
+		t.Error("Expected the replacement load to finish")
+	}
+}
+
+func TestGitOutputDisablesFSMonitor(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init failed: %v %s", err, output)
+	}
+	exec.Command("git", "-C", dir, "config", "core.fsmonitor", "touch ran").Run()
+
+	output, err := defaultGitOutput(dir, "config", "core.fsmonitor")
+	if err != nil || strings.TrimSpace(string(output)) != "false" {
+		t.Errorf("Expected core.fsmonitor overridden to false, got %q (err %v)", output, err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+// history records visited directories for back and forward navigation, like a
+// browser: visiting a new directory after going back drops the forward entries.
+type history struct {
+	entries []string
+	pos     int
+}
+
+// visit records path as the current entry. Visiting the current entry again
+// records nothing, so bouncing in place does not fill the history. When limit
+// is positive the oldest entries are dropped to keep at most limit.
+func (h *history) visit(path string, limit int) {
+	if len(h.entries) > 0 && h.entries[h.pos] == path {
+		return
+	}
+	if len(h.entries) > 0 {
+		h.entries = h.entries[:h.pos+1]
+	}
+	h.entries = append(h.entries, path)
+	if limit > 0 && len(h.entries) > limit {
+		h.entries = append([]string(nil), h.entries[len(h.entries)-limit:]...)
+	}
+	h.pos = len(h.entries) - 1
+}
+
+// step moves delta entries back (negative) or forward and returns the entry
+// there, or false when there is none.
+func (h *history) step(delta int) (string, bool) {
+	pos := h.pos + delta
+	if pos < 0 || pos >= len(h.entries) {
+		return "", false
+	}
+	h.pos = pos
+	return h.entries[pos], true
+}
+
+// GoBack returns to the previously visited directory.
+func (n *Navigator) GoBack() error {
+	return n.stepHistory(-1)
+}
+
+// GoForward returns to the directory left by GoBack.
+func (n *Navigator) GoForward() error {
+	return n.stepHistory(1)
+}
+
+// stepHistory moves through the history, keeping the position when the
+// directory cannot be entered.
+func (n *Navigator) stepHistory(delta int) error {
+	path, ok := n.history.step(delta)
+	if !ok {
+		n.SetStatusMessage("No more history")
+		return nil
+	}
+	if err := n.changeDirectory(path); err != nil {
+		n.history.step(-delta)
+		return err
+	}
+	return nil
+}
+
+// ToggleLastDir switches to the previous directory, like cd - in a shell,
+// selecting the item that was selected there. It is independent of the back
+// and forward history, so pressing it again returns.
+func (n *Navigator) ToggleLastDir() error {
+	if n.lastDir == "" {
+		n.SetStatusMessage("No previous directory")
+		return nil
+	}
+	selected := n.lastSelected
+	if err := n.changeDirectory(n.lastDir); err != nil {
+		return err
+	}
+	n.selectName(selected)
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryDedup(t *testing.T) {
+	var h history
+	for _, path := range []string{"/a", "/b", "/b", "/a", "/a", "/b"} {
+		h.visit(path, 0)
+	}
+	if want := []string{"/a", "/b", "/a", "/b"}; !reflect.DeepEqual(h.entries, want) {
+		t.Errorf("Expected consecutive duplicates to collapse to %v, got %v", want, h.entries)
+	}
+
+	// Returning to the current entry after going back records nothing new
+	h.step(-1)
+	h.visit("/a", 0)
+	if len(h.entries) != 4 || h.pos != 2 {
+		t.Errorf("Expected revisiting the current entry to keep the history, got %v at %d", h.entries, h.pos)
+	}
+	// A new visit drops the forward entries
+	h.visit("/c", 0)
+	if want := []string{"/a", "/b", "/a", "/c"}; !reflect.DeepEqual(h.entries, want) {
+		t.Errorf("Expected forward entries to be replaced, got %v", h.entries)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	var h history
+	for _, path := range []string{"/a", "/b", "/c", "/d", "/e"} {
+		h.visit(path, 3)
+	}
+	if want := []string{"/c", "/d", "/e"}; !reflect.DeepEqual(h.entries, want) {
+		t.Errorf("Expected the oldest entries to be dropped, got %v", h.entries)
+	}
+	if path, ok := h.step(-1); !ok || path != "/d" {
+		t.Errorf("Expected going back to reach /d, got %q", path)
+	}
+}
+
+func TestGoBackForward(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	os.Mkdir(filepath.Join(tempDir, "dir1", "sub"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "dir1")
+	nav.OpenSelected()
+	selectByName(t, nav, "sub")
+	nav.OpenSelected()
+
+	nav.GoBack()
+	if want := filepath.Join(tempDir, "dir1"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected back to reach %s, got %s", want, nav.GetCurrentPath())
+	}
+	nav.GoBack()
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected back to reach %s, got %s", tempDir, nav.GetCurrentPath())
+	}
+	nav.GoBack()
+	if nav.GetStatusMessage() != "No more history" || nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected to stay at the oldest entry, got %s (%q)", nav.GetCurrentPath(), nav.GetStatusMessage())
+	}
+	nav.GoForward()
+	nav.GoForward()
+	if want := filepath.Join(tempDir, "dir1", "sub"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected forward to reach %s, got %s", want, nav.GetCurrentPath())
+	}
+}
+
+func TestToggleLastDir(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	b := filepath.Join(tempDir, "dir2")
+	os.WriteFile(filepath.Join(b, "x.txt"), []byte("content"), 0644)
+	os.WriteFile(filepath.Join(b, "y.txt"), []byte("content"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	if err := nav.ToggleLastDir(); err != nil || nav.GetStatusMessage() != "No previous directory" {
+		t.Errorf("Expected a notice without a previous directory, got %q (err %v)", nav.GetStatusMessage(), err)
+	}
+
+	// A -> B, selecting y.txt in B
+	selectByName(t, nav, "dir2")
+	nav.OpenSelected()
+	selectByName(t, nav, "y.txt")
+
+	nav.ToggleLastDir()
+	if nav.GetCurrentPath() != tempDir {
+		t.Fatalf("Expected to toggle back to %s, got %s", tempDir, nav.GetCurrentPath())
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "dir2" {
+		t.Errorf("Expected dir2 to be selected again in A, got %v", selected)
+	}
+
+	nav.ToggleLastDir()
+	if nav.GetCurrentPath() != b {
+		t.Fatalf("Expected to toggle to %s, got %s", b, nav.GetCurrentPath())
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "y.txt" {
+		t.Errorf("Expected y.txt to be selected again in B, got %v", selected)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// onSelectDelay is how long the selection must stay put before the on_select
+// command runs, so holding an arrow key does not start a process per item.
+const onSelectDelay = 150 * time.Millisecond
+
+// debouncer runs only the last of a burst of calls, once delay has passed
+// without another one.
+type debouncer struct {
+	delay time.Duration
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// Trigger schedules fn, replacing anything scheduled before that has not run yet.
+func (d *debouncer) Trigger(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, fn)
+}
+
+// Stop cancels a scheduled call.
+func (d *debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// NotifySelection runs the on_select command with the selected path once the
+// selection settles. It does nothing when the selection has not moved since the
+// last call, no command is configured, --no-exec is set or the directory is
+// remote. The command's errors are ignored.
+func (n *Navigator) NotifySelection() {
+	command := strings.Fields(n.config.OnSelect)
+	selectedItem := n.GetSelectedItem()
+	if len(command) == 0 || n.config.NoExec || n.isRemote() || selectedItem == nil || selectedItem.Path == n.onSelectPath {
+		return
+	}
+	n.onSelectPath = selectedItem.Path
+
+	path := selectedItem.Path
+	run := n.runCommand
+	n.onSelect.Trigger(func() {
+		cmd := exec.Command(command[0], append(command[1:], path)...)
+		if run(cmd) == nil && cmd.Process != nil {
+			// Reap the process; its exit status does not matter
+			cmd.Wait()
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncerRunsLastCall(t *testing.T) {
+	d := &debouncer{delay: 20 * time.Millisecond}
+	var calls atomic.Int32
+	var last atomic.Int32
+	for i := 1; i <= 5; i++ {
+		i := i
+		d.Trigger(func() {
+			calls.Add(1)
+			last.Store(int32(i))
+		})
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if calls.Load() != 1 || last.Load() != 5 {
+		t.Errorf("Expected only the last call to run, got %d calls ending with %d", calls.Load(), last.Load())
+	}
+
+	d.Trigger(func() { calls.Add(1) })
+	d.Stop()
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Errorf("Expected a stopped call not to run, got %d calls", calls.Load())
+	}
+}
+
+func TestNotifySelectionRunsCommand(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("content"), 0644)
+	}
+
+	nav, _ := NewNavigator(dir)
+	nav.ScanDirectory()
+	cfg := nav.GetConfig()
+	cfg.OnSelect = "tmux send-keys -t preview"
+	nav.SetConfig(cfg)
+	nav.onSelect = &debouncer{delay: 20 * time.Millisecond}
+
+	var mu sync.Mutex
+	var runs [][]string
+	nav.runCommand = func(cmd *exec.Cmd) error {
+		mu.Lock()
+		defer mu.Unlock()
+		runs = append(runs, cmd.Args)
+		return nil
+	}
+
+	// Moving quickly through the listing runs the command once, for the last item
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		selectByName(t, nav, name)
+		nav.NotifySelection()
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := [][]string{{"tmux", "send-keys", "-t", "preview", filepath.Join(dir, "c.txt")}}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("Expected on_select runs %q, got %q", want, runs)
+	}
+}
+
+func TestNotifySelectionUnchanged(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	nav.ScanDirectory()
+	cfg := nav.GetConfig()
+	cfg.OnSelect = "echo"
+	nav.SetConfig(cfg)
+	nav.onSelect = &debouncer{delay: time.Millisecond}
+
+	var runs atomic.Int32
+	nav.runCommand = func(cmd *exec.Cmd) error {
+		runs.Add(1)
+		return nil
+	}
+
+	// Redrawing without moving the selection does not run the command again
+	nav.NotifySelection()
+	time.Sleep(20 * time.Millisecond)
+	nav.NotifySelection()
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != 1 {
+		t.Errorf("Expected one run for an unchanged selection, got %d", runs.Load())
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders for image.Decode
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// ImageProtocol is a terminal graphics protocol used to show image previews.
+type ImageProtocol int
+
+const (
+	ImageNone ImageProtocol = iota
+	ImageKitty
+)
+
+// maxImageBytes bounds the size of image files the preview will decode.
+const maxImageBytes = 16 * 1024 * 1024
+
+// kittyChunkSize is the largest base64 payload kitty accepts per escape code.
+const kittyChunkSize = 4096
+
+// kittyImageDelete removes every image nav has placed.
+const kittyImageDelete = "\x1b_Ga=d,q=2\x1b\\"
+
+// detectImageProtocol reports which graphics protocol the terminal supports,
+// judging from the environment. Terminal multiplexers hide the outer terminal
+// and need passthrough, so they get no image support.
+func detectImageProtocol(getenv func(string) string) ImageProtocol {
+	if getenv("TMUX") != "" || strings.HasPrefix(getenv("TERM"), "screen") {
+		return ImageNone
+	}
+	if getenv("KITTY_WINDOW_ID") != "" || getenv("TERM") == "xterm-kitty" {
+		return ImageKitty
+	}
+	switch strings.ToLower(getenv("TERM_PROGRAM")) {
+	case "ghostty", "wezterm":
+		return ImageKitty
+	}
+	return ImageNone
+}
+
+// imageFormat names the image format identified by a file's leading bytes, or
+// returns "" when the header is not a supported image.
+func imageFormat(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(header, []byte{0xff, 0xd8, 0xff}):
+		return "jpeg"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "gif"
+	}
+	return ""
+}
+
+// SetImageProtocol sets the graphics protocol used for image previews.
+func (n *Navigator) SetImageProtocol(protocol ImageProtocol) {
+	n.imageProtocol = protocol
+}
+
+// TogglePreviewImages switches image previews on or off.
+func (n *Navigator) TogglePreviewImages() {
+	n.config.PreviewImages = !n.config.PreviewImages
+}
+
+// PreviewImage returns the selected file as PNG data when it is an image and
+// the terminal can show it. Other files report false and get a text preview.
+func (n *Navigator) PreviewImage() ([]byte, bool) {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil || selectedItem.IsDir || n.imageProtocol == ImageNone || !n.config.PreviewImages {
+		return nil, false
+	}
+	if selectedItem.Path != n.imagePath {
+		n.imagePath = selectedItem.Path
+		n.imageData, _ = loadPNG(n.source, selectedItem.Path)
+	}
+	return n.imageData, n.imageData != nil
+}
+
+// loadPNG reads an image file and returns it PNG-encoded, as kitty expects.
+// PNG files are passed through; JPEG and GIF files are decoded and re-encoded.
+func loadPNG(source FileSource, path string) ([]byte, error) {
+	info, err := source.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxImageBytes {
+		return nil, fmt.Errorf("not an image preview candidate")
+	}
+
+	file, err := source.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageBytes))
+	if err != nil {
+		return nil, err
+	}
+	switch imageFormat(data) {
+	case "png":
+		return data, nil
+	case "":
+		return nil, fmt.Errorf("not an image")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// kittyImageSequence builds the escape codes that draw PNG data scaled to fit
+// cols by rows cells at the cursor, without moving the cursor. The payload is
+// split into chunks as the kitty graphics protocol requires.
+func kittyImageSequence(data []byte, cols, rows int) string {
+	payload := base64.StdEncoding.EncodeToString(data)
+	var sb strings.Builder
+	for first := true; first || payload != ""; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		payload = payload[len(chunk):]
+
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,c=%d,r=%d,C=1,q=2,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return sb.String()
+}
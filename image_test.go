@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectImageProtocol(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want ImageProtocol
+	}{
+		{map[string]string{}, ImageNone},
+		{map[string]string{"TERM": "xterm-256color"}, ImageNone},
+		{map[string]string{"TERM": "xterm-kitty"}, ImageKitty},
+		{map[string]string{"KITTY_WINDOW_ID": "1"}, ImageKitty},
+		{map[string]string{"TERM_PROGRAM": "ghostty"}, ImageKitty},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ImageKitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, ImageNone},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-1000/default,1,0"}, ImageNone},
+		{map[string]string{"KITTY_WINDOW_ID": "1", "TERM": "screen-256color"}, ImageNone},
+	}
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		if got := detectImageProtocol(getenv); got != tt.want {
+			t.Errorf("detectImageProtocol(%v) expected %d, got %d", tt.env, tt.want, got)
+		}
+	}
+}
+
+func TestImageFormat(t *testing.T) {
+	tests := map[string]string{
+		"\x89PNG\r\n\x1a\nrest": "png",
+		"\xff\xd8\xff\xe0":      "jpeg",
+		"GIF89a...":             "gif",
+		"GIF87a...":             "gif",
+		"plain text":            "",
+		"\x89PN":                "",
+	}
+	for header, want := range tests {
+		if got := imageFormat([]byte(header)); got != want {
+			t.Errorf("imageFormat(%q) expected %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestKittyImageSequenceChunks(t *testing.T) {
+	seq := kittyImageSequence(bytes.Repeat([]byte{1}, 5000), 20, 10)
+	chunks := strings.Split(strings.TrimSuffix(seq, "\x1b\\"), "\x1b\\")
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks for 5000 bytes, got %d", len(chunks))
+	}
+	if !strings.HasPrefix(chunks[0], "\x1b_Ga=T,f=100,c=20,r=10,C=1,q=2,m=1;") {
+		t.Errorf("Unexpected first chunk header %q", chunks[0][:40])
+	}
+	if !strings.HasPrefix(chunks[1], "\x1b_Gm=0;") {
+		t.Errorf("Expected the last chunk to end the transfer, got %q", chunks[1][:10])
+	}
+
+	if seq := kittyImageSequence([]byte{1, 2, 3}, 1, 1); strings.Count(seq, "\x1b_G") != 1 || !strings.Contains(seq, "m=0;AQID") {
+		t.Errorf("Expected a single chunk for small data, got %q", seq)
+	}
+}
+
+func TestPreviewImage(t *testing.T) {
+	tempDir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	var pngData, jpegData bytes.Buffer
+	png.Encode(&pngData, img)
+	jpeg.Encode(&jpegData, img, nil)
+	os.WriteFile(filepath.Join(tempDir, "a.png"), pngData.Bytes(), 0644)
+	os.WriteFile(filepath.Join(tempDir, "b.jpg"), jpegData.Bytes(), 0644)
+	os.WriteFile(filepath.Join(tempDir, "c.txt"), []byte("text"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// Without a supported terminal everything falls back to the text preview
+	selectByName(t, nav, "a.png")
+	if _, ok := nav.PreviewImage(); ok {
+		t.Error("Expected no image preview without a graphics protocol")
+	}
+
+	nav.SetImageProtocol(ImageKitty)
+	if data, ok := nav.PreviewImage(); !ok || !bytes.Equal(data, pngData.Bytes()) {
+		t.Error("Expected the PNG file to be passed through")
+	}
+	selectByName(t, nav, "b.jpg")
+	if data, ok := nav.PreviewImage(); !ok || imageFormat(data) != "png" {
+		t.Error("Expected the JPEG file to be converted to PNG")
+	}
+	selectByName(t, nav, "c.txt")
+	if _, ok := nav.PreviewImage(); ok {
+		t.Error("Expected no image preview for a text file")
+	}
+
+	nav.TogglePreviewImages()
+	selectByName(t, nav, "a.png")
+	if _, ok := nav.PreviewImage(); ok {
+		t.Error("Expected no image preview once image previews are off")
+	}
+}
@@ -3,35 +3,108 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run runs nav and returns its exit status. Everything it sets up, such as
+// the screen and an ssh session, is torn down by the time it returns.
+func run() int {
 	// Handle help flag
 	if len(os.Args) > 1 && (os.Args[1] == "--help" || os.Args[1] == "-h") {
 		showHelp()
-		return
+		return 0
+	}
+
+	// Open the config file in the editor instead of browsing. The flags are
+	// looked at before the config is loaded, so a config with errors in it can
+	// still be opened to fix them; a bad flag is reported further down
+	flags := DefaultConfig()
+	if _, err := parseArgs(os.Args[1:], &flags); err == nil && flags.EditConfig {
+		path, err := ensureConfigFile()
+		if err == nil {
+			err = runAttached(editConfigCommand(path))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot edit config: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	// Load settings from the config file, then let flags override them
+	// Without a home or config directory the defaults are used and a notice is shown
+	cfg := DefaultConfig()
+	var configNotice string
+	if path, err := configPath(); err == nil {
+		if cfg, err = LoadConfig(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading config '%s': %v\n", path, err)
+			return 1
+		}
+	} else {
+		configNotice = fmt.Sprintf("Config not loaded: %v", err)
+	}
+
+	// $NAV_OP_LOG overrides the op_log setting
+	if path := os.Getenv("NAV_OP_LOG"); path != "" {
+		cfg.OpLogPath = path
+	}
+
+	// $NAV_SELECTION_STYLE overrides the selection_style setting
+	if style := os.Getenv("NAV_SELECTION_STYLE"); style != "" {
+		if err := parseSelectionStyle(style, &cfg.SelectionStyle); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in $NAV_SELECTION_STYLE: %v\n", err)
+			return 1
+		}
 	}
 
 	// Get starting directory from command line or use current directory
-	startPath := "."
-	if len(os.Args) > 1 {
-		startPath = os.Args[1]
+	startPath, err := parseArgs(os.Args[1:], &cfg)
+	var remote remoteArg
+	isRemote := false
+	if err == nil {
+		remote, isRemote, err = parseRemoteArg(startPath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\nRun 'nav --help' for usage.\n", err)
+		return 1
+	}
+
+	// An sftp:// path is browsed over ssh, connecting before the screen is set
+	// up so ssh can ask for a password
+	var source *sftpSource
+	if isRemote {
+		if source, err = dialSFTP(remote); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		defer source.Close()
+		startPath = source.Resolve(remote.Path)
+	} else {
+		startPath = resolveStartPath(startPath)
 	}
 
 	// Initialize tcell screen
 	screen, err := tcell.NewScreen()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating screen: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 	if err = screen.Init(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing screen: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 	defer screen.Fini()
+	screen.EnableMouse(tcell.MouseMotionEvents)
 
 	// Set up default style
 	defStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
@@ -42,7 +115,34 @@ func main() {
 	if err != nil {
 		screen.Fini()
 		fmt.Fprintf(os.Stderr, "Error creating navigator: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+	navigator.SetAsync(func(apply func()) {
+		screen.PostEvent(&resultEvent{when: time.Now(), apply: apply})
+	})
+	if source != nil {
+		navigator.SetSource(source)
+		source.ReportStderr(func(line string) {
+			screen.PostEvent(&resultEvent{when: time.Now(), apply: func() {
+				navigator.SetStatusMessage("ssh: " + line)
+			}})
+		})
+	}
+	navigator.SetConfig(cfg)
+	if viewsFile, err := defaultViewsFile(); err == nil {
+		navigator.SetViewsFile(viewsFile)
+	}
+	navigator.SetImageProtocol(detectImageProtocol(os.Getenv))
+	navigator.SetForegroundRunner(func(cmd *exec.Cmd) error {
+		// Hand the terminal to the program, e.g. an editor, until it exits
+		if err := screen.Suspend(); err != nil {
+			return err
+		}
+		defer screen.Resume()
+		return runAttached(cmd)
+	})
+	if cfg.OutputPath != "" {
+		navigator.SetOutput(appendWriter{path: cfg.OutputPath})
 	}
 
 	// Initial directory scan
@@ -53,29 +153,145 @@ func main() {
 		} else {
 			fmt.Fprintf(os.Stderr, "Cannot read directory '%s': %v\n", navigator.GetCurrentPath(), err)
 		}
-		os.Exit(1)
+		return 1
 	}
+	if configNotice != "" {
+		navigator.SetStatusMessage(configNotice)
+	}
+
+	// Periodically rescan when an auto-refresh interval is configured
+	stopRefresh := startAutoRefresh(time.Duration(cfg.RefreshInterval)*time.Second, func() {
+		screen.PostEvent(&refreshEvent{when: time.Now()})
+	})
+	defer stopRefresh()
 
 	// Main event loop
+	var prevButtons tcell.ButtonMask
+	var lastMessageAt time.Time
+	var shownImage string
+	defer func() { clearPreviewImage(screen, shownImage) }()
 	for {
 		drawUI(screen, navigator, defStyle)
+		shownImage = showPreviewImage(screen, navigator, shownImage)
+		navigator.NotifySelection()
 
 		ev := screen.PollEvent()
 		switch ev := ev.(type) {
 		case *tcell.EventKey:
-			if navigator.GetSearchMode() {
+			if navigator.GetPrompt() != nil {
+				handlePromptModeKey(ev, navigator)
+			} else if navigator.IsMessagesOpen() {
+				handleMessagesModeKey(ev, navigator)
+			} else if navigator.IsDetailsOpen() {
+				handleDetailsModeKey(ev, navigator)
+			} else if navigator.IsPreviewFocused() {
+				handlePreviewModeKey(ev, navigator)
+			} else if navigator.GetSearchMode() {
 				if handleSearchModeKey(ev, navigator) {
-					return // Exit requested
+					return quitStatus(navigator) // Exit requested
 				}
 			} else {
 				if handleNormalModeKey(ev, navigator) {
-					return // Exit requested
+					return quitStatus(navigator) // Exit requested
 				}
 			}
+		case *tcell.EventMouse:
+			_, h := screen.Size()
+			handleMouseEvent(ev, navigator, h, &prevButtons)
+		case *refreshEvent:
+			// Keep the current listing if the rescan fails; the next tick retries
+			navigator.Refresh()
+		case *resultEvent:
+			ev.apply()
 		case *tcell.EventResize:
 			// Just redraw on resize
 			continue
 		}
+
+		// Redraw once a new status message expires so it disappears on its own
+		if messageAt := navigator.GetStatusMessageTime(); messageAt != lastMessageAt {
+			lastMessageAt = messageAt
+			time.AfterFunc(statusMessageDuration, func() {
+				screen.PostEvent(tcell.NewEventInterrupt(nil))
+			})
+		}
+
+		// Print the matched path for the caller when an exit pattern fired
+		if exitPath := navigator.GetExitPath(); exitPath != "" {
+			clearPreviewImage(screen, shownImage)
+			shownImage = ""
+			screen.Fini()
+			fmt.Println(exitPath)
+			return 0
+		}
+	}
+}
+
+// quitStatus returns the exit status for quitting: 1 when --pick ends without
+// a choice, so a script can tell cancelling apart from picking.
+func quitStatus(navigator *Navigator) int {
+	if navigator.GetConfig().Pick {
+		return 1
+	}
+	return 0
+}
+
+// handleDetailsModeKey handles keyboard input while the details popup is open.
+func handleDetailsModeKey(ev *tcell.EventKey, navigator *Navigator) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		navigator.ToggleDetails()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'i', 'q':
+			navigator.ToggleDetails()
+		case '#':
+			navigator.ToggleDetailsIDs()
+		}
+	}
+}
+
+// handleMessagesModeKey handles keyboard input while the message history is open.
+func handleMessagesModeKey(ev *tcell.EventKey, navigator *Navigator) {
+	if ev.Key() == tcell.KeyEscape || ev.Rune() == 'H' || ev.Rune() == 'q' {
+		navigator.ToggleMessages()
+	}
+}
+
+// handlePreviewModeKey handles keyboard input while the preview pane has the
+// line cursor for copying a range.
+func handlePreviewModeKey(ev *tcell.EventKey, navigator *Navigator) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		navigator.UnfocusPreview()
+	case tcell.KeyUp:
+		navigator.MovePreviewCursor(-1)
+	case tcell.KeyDown:
+		navigator.MovePreviewCursor(1)
+	case tcell.KeyPgUp:
+		navigator.MovePreviewCursor(-10)
+	case tcell.KeyPgDn:
+		navigator.MovePreviewCursor(10)
+	case tcell.KeyEnter:
+		copyPreviewRange(navigator)
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'v', ' ':
+			navigator.TogglePreviewRange()
+		case 'y':
+			copyPreviewRange(navigator)
+		case 'q':
+			navigator.UnfocusPreview()
+		}
+	}
+}
+
+// copyPreviewRange copies the selected preview lines and reports the result.
+func copyPreviewRange(navigator *Navigator) {
+	if count, err := navigator.CopyPreviewRange(); err != nil {
+		navigator.SetStatusMessage(fmt.Sprintf("Copy failed: %v", err))
+	} else {
+		navigator.SetStatusMessage(fmt.Sprintf("Copied %d lines", count))
 	}
 }
 
@@ -84,6 +300,22 @@ func handleSearchModeKey(ev *tcell.EventKey, navigator *Navigator) bool {
 	switch ev.Key() {
 	case tcell.KeyEscape:
 		navigator.ToggleSearchMode()
+	case tcell.KeyCtrlP:
+		navigator.ToggleSearchPaths()
+	case tcell.KeyTab:
+		navigator.ToggleSearchHighlight()
+	case tcell.KeyUp:
+		if navigator.GetConfig().SearchHighlight {
+			navigator.PrevMatch()
+		} else {
+			navigator.MoveSelection(-1)
+		}
+	case tcell.KeyDown:
+		if navigator.GetConfig().SearchHighlight {
+			navigator.NextMatch()
+		} else {
+			navigator.MoveSelection(1)
+		}
 	case tcell.KeyBackspace, tcell.KeyBackspace2:
 		searchTerm := navigator.GetSearchTerm()
 		if len(searchTerm) > 0 {
@@ -96,20 +328,66 @@ func handleSearchModeKey(ev *tcell.EventKey, navigator *Navigator) bool {
 	return false
 }
 
+// handlePromptModeKey handles keyboard input while a prompt is open.
+func handlePromptModeKey(ev *tcell.EventKey, navigator *Navigator) {
+	prompt := navigator.GetPrompt()
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		navigator.CancelPrompt()
+	case tcell.KeyEnter:
+		if err := navigator.SubmitPrompt(); err != nil {
+			navigator.SetStatusMessage(fmt.Sprintf("Error: %v", err))
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		navigator.DeletePromptBackward()
+	case tcell.KeyLeft:
+		navigator.SetPromptCursor(prompt.Cursor - 1)
+	case tcell.KeyRight:
+		navigator.SetPromptCursor(prompt.Cursor + 1)
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		navigator.SetPromptCursor(0)
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		navigator.SetPromptCursor(len([]rune(prompt.Text)))
+	case tcell.KeyRune:
+		navigator.InsertPromptText(string(ev.Rune()))
+	}
+}
+
 // handleNormalModeKey handles keyboard input in normal mode.
 func handleNormalModeKey(ev *tcell.EventKey, navigator *Navigator) bool {
 	switch ev.Key() {
 	case tcell.KeyUp:
-		navigator.MoveSelection(-1)
+		reportOpenError(navigator, navigator.StepSelection(-1))
 	case tcell.KeyDown:
-		navigator.MoveSelection(1)
+		reportOpenError(navigator, navigator.StepSelection(1))
 	case tcell.KeyEnter:
-		if err := navigator.OpenSelected(); err != nil {
-			if os.IsPermission(err) {
-				fmt.Fprintf(os.Stderr, "\nPermission denied: Cannot access the selected item\n")
-			} else {
-				fmt.Fprintf(os.Stderr, "\nError opening selected item: %v\n", err)
+		if ev.Modifiers()&tcell.ModAlt != 0 {
+			confirmBigDir(navigator, navigator.EnterSelected)
+		} else {
+			openSelected(navigator)
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		goUp(navigator)
+	case tcell.KeyTab:
+		if err := navigator.ToggleLastDir(); err != nil {
+			navigator.SetStatusMessage(fmt.Sprintf("Cannot open: %v", err))
+		}
+	case tcell.KeyLeft, tcell.KeyRight:
+		if ev.Modifiers()&tcell.ModAlt == 0 {
+			// Without Alt, expand and collapse directories in place
+			if ev.Key() == tcell.KeyLeft {
+				navigator.CollapseSelected()
+			} else if err := navigator.ExpandSelected(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Cannot expand: %v", err))
 			}
+			break
+		}
+		step := navigator.GoBack
+		if ev.Key() == tcell.KeyRight {
+			step = navigator.GoForward
+		}
+		if err := step(); err != nil {
+			navigator.SetStatusMessage(fmt.Sprintf("Cannot open: %v", err))
 		}
 	case tcell.KeyRune:
 		switch ev.Rune() {
@@ -117,74 +395,1029 @@ func handleNormalModeKey(ev *tcell.EventKey, navigator *Navigator) bool {
 			return true // Exit
 		case '/':
 			navigator.ToggleSearchMode()
+		case 'h':
+			goUp(navigator)
+		case 's':
+			navigator.CycleSortMode()
+		case 't':
+			if err := navigator.EmitSelected(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Output failed: %v", err))
+			} else if selectedItem := navigator.GetSelectedItem(); selectedItem != nil {
+				navigator.SetStatusMessage("Sent " + selectedItem.Name)
+			}
+		case 'y':
+			startCopyRelativePrompt(navigator)
+		case 'P':
+			if err := navigator.SetDefaultStart(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Pin failed: %v", err))
+			} else {
+				navigator.SetStatusMessage("Pinned as the default start: " + navigator.GetCurrentPath())
+			}
+		case 'T':
+			if lines, err := navigator.CopyTree(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Copy failed: %v", err))
+			} else {
+				navigator.SetStatusMessage(fmt.Sprintf("Copied tree (%d lines)", lines))
+			}
+		case 'W':
+			startMarkdownTreePrompt(navigator)
+		case 'b':
+			stashSelected(navigator)
+		case 'Y':
+			if err := navigator.CopyCurrentPath(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Copy failed: %v", err))
+			} else {
+				navigator.SetStatusMessage("Copied " + navigator.GetCurrentPath())
+			}
+		case 'x':
+			navigator.ToggleExtFilter()
+		case 'H':
+			navigator.ToggleMessages()
+		case 'A':
+			navigator.ToggleSortIgnoreCase()
+		case 'K':
+			if count, err := navigator.CopyMarkedPaths(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Copy failed: %v", err))
+			} else if count > 0 {
+				navigator.SetStatusMessage(fmt.Sprintf("Copied %d paths", count))
+			}
+		case 'R':
+			toggleSessionRoot(navigator)
+		case 'g':
+			navigator.StartPrompt("Go to: ", "", navigator.GoToPath)
+		case '~':
+			if err := navigator.GoHome(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Cannot open: %v", err))
+			}
+		case '`':
+			if err := navigator.GoRoot(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Cannot open: %v", err))
+			}
+		case '=':
+			if err := navigator.GoStart(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Cannot open: %v", err))
+			}
+		case 'M':
+			navigator.ToggleMouseHover()
+		case '@':
+			navigator.ToggleRealPath()
+		case 'F':
+			if err := navigator.ToggleFlatView(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Flat view failed: %v", err))
+			}
+		case 'B':
+			navigator.ToggleSizeOnDisk()
+		case '\\':
+			navigator.ToggleDirSlash()
+		case 'f':
+			navigator.ToggleFollowSymlinks()
+			if navigator.GetConfig().FollowSymlinks {
+				navigator.SetStatusMessage("Following symlinked directories")
+			} else {
+				navigator.SetStatusMessage("Not following symlinked directories")
+			}
+		case 'd':
+			navigator.ToggleCompactDetails()
+		case 'L':
+			navigator.SetStatusMessage("View: " + navigator.CycleViewPreset())
+		case 'V':
+			if remembered, err := navigator.ToggleDirView(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Cannot remember view: %v", err))
+			} else if remembered {
+				navigator.SetStatusMessage("Remembered this view for " + navigator.GetCurrentPath())
+			} else {
+				navigator.SetStatusMessage("Forgot the view for " + navigator.GetCurrentPath())
+			}
+		case 'p':
+			navigator.TogglePreview()
+		case 'w':
+			navigator.TogglePreviewWrap()
+		case 'v':
+			navigator.FocusPreview()
+		case '<', '>':
+			delta := 5
+			if ev.Rune() == '<' {
+				delta = -5
+			}
+			navigator.SetStatusMessage(fmt.Sprintf("Split: %d%% list", navigator.ResizePreview(delta)))
+		case 'I':
+			navigator.TogglePreviewImages()
+		case 'i':
+			if selectedItem := navigator.requireSelection(); selectedItem != nil && !selectedItem.IsParent {
+				navigator.ToggleDetails()
+			}
+		case ' ':
+			navigator.ToggleMark()
+			navigator.MoveSelection(1)
+		case '+', '-':
+			startMarkGlobPrompt(navigator, ev.Rune() == '+')
+		case 'E':
+			if err := navigator.EditConfig(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Cannot edit config: %v", err))
+			}
+		case '.':
+			navigator.PickCurrentDir()
+		case 'a':
+			navigator.SetStatusMessage(fmt.Sprintf("Marked %d items", navigator.MarkAll()))
+		case 'u':
+			navigator.SetStatusMessage(fmt.Sprintf("Unmarked %d items", navigator.ClearMarks()))
+		case 'r':
+			navigator.StartRename()
+		case 'n':
+			navigator.StartPrompt("New file: ", "", navigator.CreateFile)
+		case 'm':
+			startMovePrompt(navigator)
+		case 'S':
+			showMarkedSize(navigator)
+		case 'c', 'C':
+			startChmodPrompt(navigator, ev.Rune() == 'C')
 		case 'o':
 			if err := navigator.OpenSelectedInTerminal(); err != nil {
-				fmt.Fprintf(os.Stderr, "\nError opening terminal: %v\n", err)
+				navigator.SetStatusMessage(fmt.Sprintf("Error opening terminal: %v", err))
+			}
+		case 'O':
+			openMarkedInTerminal(navigator)
+		case 'N':
+			if err := navigator.OpenNewInstance(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Error opening new instance: %v", err))
+			}
+		case '[':
+			if err := navigator.PrevSibling(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Error opening sibling directory: %v", err))
+			}
+		case ']':
+			if err := navigator.NextSibling(); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Error opening sibling directory: %v", err))
+			}
+		case 'z', 'Z':
+			found := navigator.NextHidden
+			if ev.Rune() == 'Z' {
+				found = navigator.PrevHidden
+			}
+			if !found() {
+				navigator.SetStatusMessage("No more hidden entries")
+			}
+		default:
+			// Keys nav does not use itself can run user commands
+			if _, err := navigator.RunUserCommand(ev.Rune()); err != nil {
+				navigator.SetStatusMessage(fmt.Sprintf("Command failed: %v", err))
 			}
 		}
 	}
 	return false
 }
 
+// Glyphs holds the characters used to draw tree prefixes, truncated names and the scrollbar.
+type Glyphs struct {
+	Branch      string
+	LastBranch  string
+	Trunk       string
+	Ellipsis    string
+	Arrow       string
+	CameFrom    string
+	ScrollTrack rune
+	ScrollThumb rune
+	MoreAbove   string
+	MoreBelow   string
+}
+
+var (
+	unicodeGlyphs = Glyphs{Branch: "├── ", LastBranch: "└── ", Trunk: "│   ", Ellipsis: "…", Arrow: "→ ", CameFrom: " ‹", ScrollTrack: '│', ScrollThumb: '█', MoreAbove: "▲", MoreBelow: "▼"}
+	asciiGlyphs   = Glyphs{Branch: "|-- ", LastBranch: "`-- ", Trunk: "|   ", Ellipsis: "...", Arrow: "-> ", CameFrom: " <", ScrollTrack: '|', ScrollThumb: '#', MoreAbove: "^", MoreBelow: "v"}
+)
+
+// glyphsFor returns the glyph set for the given ASCII setting.
+func glyphsFor(ascii bool) Glyphs {
+	if ascii {
+		return asciiGlyphs
+	}
+	return unicodeGlyphs
+}
+
+// treePrefix returns the tree-style prefix for an item.
+func treePrefix(isLast bool, glyphs Glyphs) string {
+	if isLast {
+		return glyphs.LastBranch
+	}
+	return glyphs.Branch
+}
+
+// treePrefixes returns the tree-style prefix of each item, indented by its
+// depth inside expanded directories, with a trunk continuing past every
+// directory that has more entries below.
+func treePrefixes(items []FileItem, glyphs Glyphs) []string {
+	// An item is the last of its siblings when no later item at its depth
+	// comes before the listing returns to a shallower one
+	last := make([]bool, len(items))
+	var following []bool
+	for i := len(items) - 1; i >= 0; i-- {
+		depth := items[i].Depth
+		for len(following) <= depth {
+			following = append(following, false)
+		}
+		last[i] = !following[depth]
+		following = append(following[:depth], true)
+	}
+
+	prefixes := make([]string, len(items))
+	blank := strings.Repeat(" ", len([]rune(glyphs.Trunk)))
+	indents := []string{""}
+	for i, item := range items {
+		// A search can list an entry without its directory; pad it anyway
+		for len(indents) <= item.Depth {
+			indents = append(indents, indents[len(indents)-1]+blank)
+		}
+		indent := indents[item.Depth]
+		prefixes[i] = indent + treePrefix(last[i], glyphs)
+		childIndent := indent + glyphs.Trunk
+		if last[i] {
+			childIndent = indent + blank
+		}
+		indents = append(indents[:item.Depth+1], childIndent)
+	}
+	return prefixes
+}
+
+// startChmodPrompt asks for an octal mode and applies it to the marked items,
+// or to the selected item when nothing is marked.
+func startChmodPrompt(navigator *Navigator, recursive bool) {
+	label := "Chmod: "
+	if recursive {
+		label = "Chmod (recursive): "
+	}
+	navigator.StartPrompt(label, "", func(text string) error {
+		mode, err := parseMode(text)
+		if err != nil {
+			return err
+		}
+		if len(navigator.GetMarkedItems()) > 0 {
+			return navigator.ChmodMarked(mode, recursive)
+		}
+		return navigator.ChmodSelected(mode, recursive)
+	})
+}
+
+// startMovePrompt asks for a destination directory and moves the marked items,
+// or the selected item, there. A move to another filesystem copies and then
+// removes the originals, so it is confirmed first.
+func startMovePrompt(navigator *Navigator) {
+	navigator.StartPrompt("Move to: ", "", func(text string) error {
+		copies, err := navigator.MoveNeedsCopy(text)
+		if err != nil {
+			return err
+		}
+		if copies == 0 {
+			return moveResolvingCollisions(navigator, text)
+		}
+		question := fmt.Sprintf("%d items are on another filesystem and will be copied, then removed. Move?", copies)
+		startConfirmPrompt(navigator, question, func() error {
+			return moveResolvingCollisions(navigator, text)
+		})
+		return nil
+	})
+}
+
+// moveResolvingCollisions moves the items into dest. With on_collision = ask,
+// each name already in dest is asked about first, one prompt at a time.
+func moveResolvingCollisions(navigator *Navigator, dest string) error {
+	if navigator.GetConfig().OnCollision != CollisionAsk {
+		return navigator.MoveItems(dest, nil)
+	}
+	names, err := navigator.MoveCollisions(dest)
+	if err != nil {
+		return err
+	}
+	askCollisions(navigator, names, make(map[string]CollisionPolicy), func(choices map[string]CollisionPolicy) error {
+		return navigator.MoveItems(dest, choices)
+	})
+	return nil
+}
+
+// stashSelected copies the selected item into the stash, asking first when
+// its name is taken there and on_collision = ask.
+func stashSelected(navigator *Navigator) {
+	name := navigator.StashCollision()
+	if navigator.GetConfig().OnCollision != CollisionAsk || name == "" {
+		if err := navigator.StashSelected(); err != nil {
+			navigator.SetStatusMessage(fmt.Sprintf("Stash failed: %v", err))
+		}
+		return
+	}
+	askCollisions(navigator, []string{name}, make(map[string]CollisionPolicy), navigator.stashSelected)
+}
+
+// askCollisions asks what to do about names[0], then the rest, and runs done
+// once every collision has a choice. A capital answer applies to all remaining.
+func askCollisions(navigator *Navigator, names []string, choices map[string]CollisionPolicy, done func(choices map[string]CollisionPolicy) error) {
+	if len(names) == 0 {
+		if err := done(choices); err != nil {
+			navigator.SetStatusMessage(fmt.Sprintf("Error: %v", err))
+		}
+		return
+	}
+	label := fmt.Sprintf("%s exists: (s)kip, (o)verwrite, (r)ename, capital for all: ", names[0])
+	navigator.StartPrompt(label, "", func(text string) error {
+		policy, all, err := parseCollisionAnswer(text)
+		if err != nil {
+			return err
+		}
+		rest := names[1:]
+		if all {
+			rest = nil
+			for _, name := range names {
+				choices[name] = policy
+			}
+		}
+		choices[names[0]] = policy
+		askCollisions(navigator, rest, choices, done)
+		return nil
+	})
+}
+
+// showMarkedSize shows the combined size of the marked items in the status bar.
+func showMarkedSize(navigator *Navigator) {
+	count := len(navigator.GetMarkedItems())
+	if count == 0 {
+		navigator.SetStatusMessage("Nothing marked")
+		return
+	}
+	total, err := navigator.MarkedTotalSize()
+	msg := fmt.Sprintf("%d marked items: %s", count, formatSize(total))
+	if err != nil {
+		msg += " (some items could not be read)"
+	}
+	navigator.SetStatusMessage(msg)
+}
+
+// startMarkGlobPrompt asks for a glob and marks (or unmarks) the visible items
+// matching it.
+func startMarkGlobPrompt(navigator *Navigator, mark bool) {
+	label, verb := "Mark glob: ", "Marked"
+	if !mark {
+		label, verb = "Unmark glob: ", "Unmarked"
+	}
+	navigator.StartPrompt(label, "", func(text string) error {
+		changed, err := navigator.MarkMatching(text, mark)
+		if err != nil {
+			return err
+		}
+		navigator.SetStatusMessage(fmt.Sprintf("%s %d items", verb, changed))
+		return nil
+	})
+}
+
+// startMarkdownTreePrompt asks where to write the current directory's tree as
+// Markdown: the clipboard when left empty, or a new file.
+func startMarkdownTreePrompt(navigator *Navigator) {
+	navigator.StartPrompt("Markdown tree to file (empty for the clipboard): ", "", func(text string) error {
+		dest := strings.TrimSpace(text)
+		lines, err := navigator.ExportMarkdownTree(dest)
+		if err != nil {
+			return err
+		}
+		if dest == "" {
+			dest = "the clipboard"
+		}
+		navigator.SetStatusMessage(fmt.Sprintf("Wrote Markdown tree (%d lines) to %s", lines, dest))
+		return nil
+	})
+}
+
+// startCopyRelativePrompt asks for a base and copies the selected item's path
+// relative to it.
+func startCopyRelativePrompt(navigator *Navigator) {
+	if navigator.requireSelection() == nil {
+		return
+	}
+	navigator.StartPrompt("Copy path relative to (s)tart dir, project (r)oot, or a path: ", "", func(text string) error {
+		base, err := navigator.resolveBase(text)
+		if err != nil {
+			return err
+		}
+		if err := navigator.CopyRelativePath(base); err != nil {
+			return err
+		}
+		navigator.SetStatusMessage("Copied path relative to " + base)
+		return nil
+	})
+}
+
+// openMarkedInTerminal opens a terminal per marked directory, asking first when
+// there are more than the configured maximum.
+func openMarkedInTerminal(navigator *Navigator) {
+	count := len(navigator.GetMarkedDirs())
+	if count > navigator.GetConfig().MaxTerminals {
+		startConfirmPrompt(navigator, fmt.Sprintf("Open %d terminals?", count), navigator.OpenMarkedInTerminal)
+		return
+	}
+	if err := navigator.OpenMarkedInTerminal(); err != nil {
+		navigator.SetStatusMessage(fmt.Sprintf("Error opening terminal: %v", err))
+	}
+}
+
+// startConfirmPrompt asks a yes/no question and runs action when answered yes.
+func startConfirmPrompt(navigator *Navigator, question string, action func() error) {
+	navigator.StartPrompt(question+" [y/N]: ", "", func(text string) error {
+		if answer := strings.ToLower(strings.TrimSpace(text)); answer == "y" || answer == "yes" {
+			return action()
+		}
+		return nil
+	})
+	navigator.GetPrompt().Confirm = true
+}
+
+// toggleSessionRoot sets the session root at the selected directory, or clears
+// it when one is set.
+func toggleSessionRoot(navigator *Navigator) {
+	if navigator.GetSessionRoot() != "" {
+		if err := navigator.ClearSessionRoot(); err != nil {
+			navigator.SetStatusMessage(fmt.Sprintf("Refresh failed: %v", err))
+			return
+		}
+		navigator.SetStatusMessage("Session root cleared")
+		return
+	}
+	if err := navigator.SetSessionRoot(); err != nil {
+		navigator.SetStatusMessage(fmt.Sprintf("Cannot set session root: %v", err))
+		return
+	}
+	navigator.SetStatusMessage("Session root: " + navigator.GetSessionRoot())
+}
+
+// goUp navigates to the parent directory, reporting any error.
+func goUp(navigator *Navigator) {
+	if err := navigator.GoUp(); err != nil {
+		if os.IsPermission(err) {
+			navigator.SetStatusMessage("Permission denied: Cannot access the parent directory")
+		} else {
+			navigator.SetStatusMessage(fmt.Sprintf("Error opening parent directory: %v", err))
+		}
+	}
+}
+
+// openSelected opens the selected item, reporting any error. Entering a big
+// directory is confirmed first.
+func openSelected(navigator *Navigator) {
+	confirmBigDir(navigator, navigator.OpenSelected)
+}
+
+// confirmBigDir calls open, such as OpenSelected or EnterSelected, reporting
+// any error, and asks first when the selected item is a big directory.
+func confirmBigDir(navigator *Navigator, open func() error) {
+	if navigator.ShouldWarnBeforeEntering() {
+		question := fmt.Sprintf("%s has more than %d entries. Enter it?", navigator.GetSelectedItem().Name, navigator.GetConfig().BigDirEntries)
+		startConfirmPrompt(navigator, question, open)
+		return
+	}
+	reportOpenError(navigator, open())
+}
+
+// reportOpenError reports a failure to open the selected item.
+func reportOpenError(navigator *Navigator, err error) {
+	if err == nil {
+		return
+	}
+	if os.IsPermission(err) {
+		navigator.SetStatusMessage("Permission denied: Cannot access the selected item")
+	} else {
+		navigator.SetStatusMessage(fmt.Sprintf("Error opening selected item: %v", err))
+	}
+}
+
+// handleMouseEvent handles clicks, wheel scrolling and hover over the item list.
+func handleMouseEvent(ev *tcell.EventMouse, navigator *Navigator, screenHeight int, prevButtons *tcell.ButtonMask) {
+	buttons := ev.Buttons()
+	pressed := buttons&tcell.Button1 != 0 && *prevButtons&tcell.Button1 == 0
+	*prevButtons = buttons
+
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		navigator.MoveSelection(-1)
+		return
+	case buttons&tcell.WheelDown != 0:
+		navigator.MoveSelection(1)
+		return
+	}
+
+	_, y := ev.Position()
+	idx := itemIndexAtRow(y, screenHeight, len(navigator.GetItems()), navigator.GetScrollOffset())
+	if idx < 0 {
+		return
+	}
+
+	hover := navigator.GetConfig().MouseHover
+	if pressed {
+		// A click opens the hovered or already-selected item, otherwise it selects it
+		shouldOpen := hover || idx == navigator.GetSelectedIndex()
+		navigator.MoveSelection(idx - navigator.GetSelectedIndex())
+		if shouldOpen {
+			openSelected(navigator)
+		}
+	} else if hover && buttons == tcell.ButtonNone {
+		navigator.MoveSelection(idx - navigator.GetSelectedIndex())
+	}
+}
+
+// itemIndexAtRow maps a screen row to the index of the item drawn there, or -1.
+func itemIndexAtRow(y, screenHeight, totalItems, scrollOffset int) int {
+	if y < 2 || y >= screenHeight-2 { // Items start at y=2 and stop above the status bar
+		return -1
+	}
+	idx := y - 2 + scrollOffset
+	if idx >= totalItems {
+		return -1
+	}
+	return idx
+}
+
 // drawUI renders the current state to the screen.
 func drawUI(screen tcell.Screen, navigator *Navigator, defStyle tcell.Style) {
 	screen.Clear()
-	_, h := screen.Size()
-
-	// Draw current path
-	drawText(screen, 0, 0, defStyle, navigator.GetCurrentPath())
+	w, h := screen.Size()
+	glyphs := glyphsFor(navigator.GetConfig().ASCII)
 
-	// Draw items
+	// Draw current path, with the resolved path beneath it when reached via a symlink
+	pathLine := navigator.GetCurrentPath()
+	if flat, truncated := navigator.IsFlatView(); truncated {
+		pathLine += fmt.Sprintf(" [flat, first %d files]", maxFlatItems)
+	} else if flat {
+		pathLine += " [flat]"
+	}
+	if navigator.GetCurrentPath() == navigator.GetSessionRoot() {
+		pathLine += " [root]"
+	}
+	if ext := navigator.ExtFilter(); ext != "" {
+		pathLine += " [*." + ext + "]"
+	}
+	// Scroll so the selection stays within the visible rows
+	cfg := navigator.GetConfig()
 	items := navigator.GetItems()
-	for i, item := range items {
-		y := i + 2 // Start drawing items from y=2
-		if y >= h-2 { // Leave space for status bar
-			break
+	visibleRows := h - 4 // Items start at y=2 and leave space for the status bar
+	navigator.EnsureVisible(visibleRows)
+	scrollOffset := navigator.GetScrollOffset()
+	above, below := scrollHints(len(items), visibleRows, scrollOffset)
+
+	// The position sits at the right edge, and the path gives way to it. The
+	// count of entries scrolled out of view above goes with it, as the row
+	// below the header may hold the real path
+	position := navigator.Position()
+	if cfg.ScrollHints && above > 0 {
+		position = fmt.Sprintf("%s %d more  %s", glyphs.MoreAbove, above, position)
+	}
+	positionX := max(w-runewidth.StringWidth(position), 0)
+	drawTextIn(screen, 0, 0, positionX-1, defStyle, pathLine, glyphs)
+	drawText(screen, positionX, 0, defStyle.Foreground(tcell.ColorGray), position, glyphs)
+	if realPath := navigator.GetRealPath(); realPath != "" && navigator.GetConfig().ShowRealPath {
+		drawText(screen, 0, 1, defStyle.Foreground(tcell.ColorGray), glyphs.Arrow+realPath, glyphs)
+	}
+
+	// Split off the preview pane on the right when enabled
+	listWidth := w
+	if navigator.GetConfig().Preview {
+		listWidth = previewX(w, navigator.GetConfig().PreviewSplit)
+		drawPreview(screen, navigator, listWidth, h, defStyle)
+	}
+
+	// Lay out metadata columns ahead of the names
+	now := time.Now()
+	columns := activeColumns(cfg)
+	var rows [][]string
+	var offsets []int
+	if len(columns) > 0 {
+		rows = columnRows(items, columns, cfg, now)
+		offsets = layoutColumns(rows, cfg.ColumnPadding, cfg.ColumnSeparator)
+	}
+
+	// Reserve the right-most list column for a scrollbar when items overflow
+	textWidth := listWidth
+	if thumbStart, thumbSize, ok := scrollbarThumb(len(items), visibleRows, scrollOffset, visibleRows); ok {
+		textWidth = listWidth - 1
+		for row := 0; row < visibleRows; row++ {
+			r, style := glyphs.ScrollTrack, defStyle.Foreground(tcell.ColorGray)
+			if row >= thumbStart && row < thumbStart+thumbSize {
+				r, style = glyphs.ScrollThumb, defStyle
+			}
+			screen.SetContent(textWidth, row+2, r, nil, style)
 		}
+	}
+
+	// Draw items
+	prefixes := treePrefixes(items, glyphs)
+	inRepo := navigator.InGitRepo()
+	for row := 0; row < visibleRows && scrollOffset+row < len(items); row++ {
+		i := scrollOffset + row
+		item := items[i]
+		y := row + 2 // Start drawing items from y=2
 
 		style := defStyle
+		marked := navigator.IsMarked(item.Path)
 		if i == navigator.GetSelectedIndex() {
-			style = defStyle.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorBlack)
+			style = selectionStyle(cfg, defStyle)
+		} else if marked {
+			style = defStyle.Foreground(tcell.ColorYellow)
+		} else if navigator.GetSearchMode() && cfg.SearchHighlight && navigator.IsSearchMatch(item) {
+			style = defStyle.Foreground(tcell.ColorGreen).Bold(true)
 		}
 
 		// Draw tree-style prefix
-		prefix := "├── "
-		if i == len(items)-1 {
-			prefix = "└── "
-		}
+		prefix := prefixes[i]
 
 		// Format display name
-		displayName := item.Name
-		if item.IsDir && displayName != "../" {
-			displayName += "/"
+		displayName := itemDisplayName(item, cfg.DirSlash)
+		if navigator.IsBigDir(item) {
+			displayName += fmt.Sprintf(" [%d+]", cfg.BigDirEntries)
+		}
+		if cfg.ShowMounts && item.MountPoint {
+			displayName += " [mount]"
+		}
+		if item.Path == navigator.GetCameFrom() {
+			displayName += glyphs.CameFrom
+		}
+		if marked {
+			displayName = "* " + displayName
+		}
+
+		nameX := 0
+		for c := range columns {
+			drawTextIn(screen, offsets[c], y, textWidth, style, rows[i][c], glyphs)
+			if cfg.ColumnSeparator != "" {
+				sepX := columnSeparatorX(offsets, c+1, cfg.ColumnPadding, cfg.ColumnSeparator)
+				drawTextIn(screen, sepX, y, textWidth, defStyle, cfg.ColumnSeparator, glyphs)
+			}
+			nameX = offsets[c+1]
+		}
+
+		// The git status letter sits in its own column before the name
+		if inRepo {
+			status := navigator.GitStatusOf(item)
+			drawTextIn(screen, nameX, y, textWidth, style.Foreground(gitStatusColor(status)), status.Indicator(), glyphs)
+			nameX += 2
+		}
+
+		if cfg.CompactDetails {
+			nameWidth := textWidth - nameX - len([]rune(prefix))
+			displayName = compactRow(displayName, compactDetails(item, cfg, now), nameWidth, glyphs.Ellipsis)
 		}
+		drawTextIn(screen, nameX, y, textWidth, style, prefix+displayName, glyphs)
+	}
 
-		drawText(screen, 0, y, style, prefix+displayName)
+	// Count the entries scrolled out of view below the list on the free row
+	// above the status bar
+	if cfg.ScrollHints && below > 0 {
+		hint := fmt.Sprintf("%s %d more", glyphs.MoreBelow, below)
+		drawTextIn(screen, max(textWidth-len([]rune(hint)), 0), visibleRows+2, textWidth, defStyle.Foreground(tcell.ColorGray), hint, glyphs)
 	}
 
 	// Draw status bar
 	statusBarY := h - 1
 	statusContent := buildStatusBar(navigator, len(items))
-	drawText(screen, 0, statusBarY, defStyle, statusContent)
+	drawText(screen, 0, statusBarY, defStyle, statusContent, glyphs)
+	if prompt := navigator.GetPrompt(); prompt != nil && !prompt.Confirm {
+		typed := string([]rune(prompt.Text)[:prompt.Cursor])
+		screen.ShowCursor(runewidth.StringWidth(prompt.Label+typed), statusBarY)
+	} else {
+		screen.HideCursor()
+	}
+
+	if navigator.IsDetailsOpen() {
+		drawDetails(screen, navigator, defStyle)
+	}
+	if navigator.IsMessagesOpen() {
+		drawMessages(screen, navigator, defStyle)
+	}
 
 	screen.Show()
 }
 
+// gitStatusColor returns the color of a git status indicator.
+func gitStatusColor(status GitStatus) tcell.Color {
+	switch status {
+	case GitUntracked, GitDeleted:
+		return tcell.ColorRed
+	case GitAdded:
+		return tcell.ColorGreen
+	case GitRenamed:
+		return tcell.ColorBlue
+	case GitModified:
+		return tcell.ColorYellow
+	case GitConflict:
+		return tcell.ColorFuchsia
+	}
+	return tcell.ColorDefault
+}
+
+// scrollbarThumb computes the position and size of the scrollbar thumb within a
+// track of trackHeight rows. It reports false when every item fits and no
+// scrollbar is needed.
+func scrollbarThumb(totalItems, visibleRows, scrollOffset, trackHeight int) (start, size int, ok bool) {
+	if totalItems <= visibleRows || visibleRows <= 0 || trackHeight <= 0 {
+		return 0, 0, false
+	}
+
+	size = trackHeight * visibleRows / totalItems
+	if size < 1 {
+		size = 1
+	}
+
+	// Map the scroll range onto the free track so the thumb touches the bottom
+	// exactly when the last item is visible
+	maxOffset := totalItems - visibleRows
+	if scrollOffset > maxOffset {
+		scrollOffset = maxOffset
+	}
+	start = scrollOffset * (trackHeight - size) / maxOffset
+	return start, size, true
+}
+
+// scrollHints returns how many of totalItems lie above and below the
+// visibleRows shown from scrollOffset.
+func scrollHints(totalItems, visibleRows, scrollOffset int) (above, below int) {
+	if visibleRows <= 0 {
+		return 0, 0
+	}
+	above = max(min(scrollOffset, totalItems), 0)
+	below = max(totalItems-scrollOffset-visibleRows, 0)
+	return above, below
+}
+
+// drawPreview renders the preview pane to the right of column x, with a border.
+func drawPreview(screen tcell.Screen, navigator *Navigator, x, h int, defStyle tcell.Style) {
+	w, _ := screen.Size()
+	borderStyle := defStyle.Foreground(tcell.ColorGray)
+	for y := 2; y < h-2; y++ {
+		screen.SetContent(x, y, tcell.RuneVLine, nil, borderStyle)
+	}
+
+	// Images are drawn by the terminal afterwards, see showPreviewImage
+	if _, ok := navigator.PreviewImage(); ok {
+		return
+	}
+
+	cfg := navigator.GetConfig()
+	lines, err := navigator.PreviewSelected()
+	if err != nil {
+		lines = []string{fmt.Sprintf("Cannot preview: %v", err)}
+	}
+	if !navigator.IsPreviewFocused() {
+		lines = wrapLines(lines, w-x-2, cfg.TabWidth, cfg.PreviewWrap)
+		for i, line := range lines {
+			y := i + 2
+			if y >= h-2 {
+				break
+			}
+			drawCells(screen, x+2, y, defStyle, line)
+		}
+		return
+	}
+
+	// Wrap line by line so the cursor and range cover whole source lines, and
+	// scroll so the cursor stays on screen
+	cursor, start, end := navigator.PreviewCursor()
+	first := max(0, cursor-(h-5))
+	y := 2
+	for i := first; i < len(lines) && y < h-2; i++ {
+		style := defStyle
+		if i >= start && i <= end {
+			style = defStyle.Reverse(true)
+		}
+		for _, row := range wrapLines(lines[i:i+1], w-x-2, cfg.TabWidth, cfg.PreviewWrap) {
+			if y >= h-2 {
+				break
+			}
+			drawCells(screen, x+2, y, style, row)
+			y++
+		}
+	}
+}
+
+// drawDetails renders the details popup as a bordered box over the listing.
+func drawDetails(screen tcell.Screen, navigator *Navigator, defStyle tcell.Style) {
+	details, err := navigator.SelectedDetails()
+	var lines []string
+	if err != nil {
+		lines = []string{fmt.Sprintf("Cannot read details: %v", err)}
+	} else if details != nil {
+		lines = detailsLines(details, navigator.GetConfig().DetailsIDs)
+	}
+
+	drawPopup(screen, lines, defStyle, glyphsFor(navigator.GetConfig().ASCII))
+}
+
+// drawMessages renders the message history popup, keeping the newest
+// messages when they do not all fit.
+func drawMessages(screen tcell.Screen, navigator *Navigator, defStyle tcell.Style) {
+	_, h := screen.Size()
+	lines := navigator.MessageLines()
+	if fit := h - 2; fit > 0 && len(lines) > fit {
+		lines = lines[len(lines)-fit:]
+	}
+	drawPopup(screen, lines, defStyle, glyphsFor(navigator.GetConfig().ASCII))
+}
+
+// drawPopup renders lines as a bordered box centered over the listing.
+func drawPopup(screen tcell.Screen, lines []string, defStyle tcell.Style, glyphs Glyphs) {
+	w, h := screen.Size()
+	boxWidth := 0
+	for _, line := range lines {
+		boxWidth = max(boxWidth, runewidth.StringWidth(line))
+	}
+	boxWidth = min(boxWidth+4, w)
+	boxHeight := min(len(lines)+2, h)
+	left, top := (w-boxWidth)/2, (h-boxHeight)/2
+	right, bottom := left+boxWidth-1, top+boxHeight-1
+
+	borderStyle := defStyle.Foreground(tcell.ColorGray)
+	for y := top; y <= bottom; y++ {
+		for x := left; x <= right; x++ {
+			screen.SetContent(x, y, ' ', nil, defStyle)
+		}
+		screen.SetContent(left, y, tcell.RuneVLine, nil, borderStyle)
+		screen.SetContent(right, y, tcell.RuneVLine, nil, borderStyle)
+	}
+	for x := left; x <= right; x++ {
+		screen.SetContent(x, top, tcell.RuneHLine, nil, borderStyle)
+		screen.SetContent(x, bottom, tcell.RuneHLine, nil, borderStyle)
+	}
+	screen.SetContent(left, top, tcell.RuneULCorner, nil, borderStyle)
+	screen.SetContent(right, top, tcell.RuneURCorner, nil, borderStyle)
+	screen.SetContent(left, bottom, tcell.RuneLLCorner, nil, borderStyle)
+	screen.SetContent(right, bottom, tcell.RuneLRCorner, nil, borderStyle)
+
+	for i, line := range lines {
+		y := top + 1 + i
+		if y >= bottom {
+			break
+		}
+		drawTextIn(screen, left+2, y, right-1, defStyle, line, glyphs)
+	}
+}
+
+// showPreviewImage draws the selected image in the preview pane when the
+// terminal supports it, replacing the image shown before. It returns a key for
+// the image now on screen, or "" when none is shown.
+func showPreviewImage(screen tcell.Screen, navigator *Navigator, shown string) string {
+	w, h := screen.Size()
+	data, _ := navigator.PreviewImage()
+	want := previewImageKey(navigator, w, h)
+	if want == shown {
+		return shown
+	}
+
+	tty, ok := screen.Tty()
+	if !ok {
+		return ""
+	}
+	var sb strings.Builder
+	if shown != "" {
+		sb.WriteString(kittyImageDelete)
+	}
+	if want != "" {
+		// Save and restore the cursor so tcell's idea of its position stays right
+		x := previewX(w, navigator.GetConfig().PreviewSplit) + 2
+		fmt.Fprintf(&sb, "\x1b7\x1b[3;%dH%s\x1b8", x+1, kittyImageSequence(data, w-x, h-4))
+	}
+	tty.Write([]byte(sb.String()))
+	return want
+}
+
+// previewImageKey returns a key for the preview image the screen should show
+// at size w×h, or "" for none. The image would cover the popups, prompts and
+// messages drawn over the preview, so none is shown while one is open.
+func previewImageKey(navigator *Navigator, w, h int) string {
+	if _, ok := navigator.PreviewImage(); !ok || !navigator.GetConfig().Preview {
+		return ""
+	}
+	if navigator.GetPrompt() != nil || navigator.IsDetailsOpen() || navigator.IsMessagesOpen() {
+		return ""
+	}
+	return fmt.Sprintf("%s@%dx%d", navigator.GetSelectedItem().Path, w, h)
+}
+
+// clearPreviewImage removes the preview image, if one is shown, so it does not
+// stay on the terminal once nav gives the screen up.
+func clearPreviewImage(screen tcell.Screen, shown string) {
+	if shown == "" {
+		return
+	}
+	if tty, ok := screen.Tty(); ok {
+		tty.Write([]byte(kittyImageDelete))
+	}
+}
+
+// drawCells draws text that already fits its space, honoring wide runes.
+func drawCells(screen tcell.Screen, x, y int, style tcell.Style, text string) {
+	for _, r := range text {
+		screen.SetContent(x, y, r, nil, style)
+		x += runewidth.RuneWidth(r)
+	}
+}
+
 // buildStatusBar builds the status bar content.
 func buildStatusBar(navigator *Navigator, totalItems int) string {
+	hints := modeHints[currentMode(navigator)]
+	if prompt := navigator.GetPrompt(); prompt != nil {
+		return prompt.Label + prompt.Text + "  • " + hints
+	}
+	if navigator.GetSearchMode() {
+		label := "Search"
+		if navigator.GetConfig().SearchHighlight {
+			label = "Highlight"
+		}
+		if navigator.GetConfig().SearchPaths {
+			label += " (paths)"
+		}
+		return fmt.Sprintf("%s: %s  • %s", label, navigator.GetSearchTerm(), hints)
+	}
+	if msg := navigator.GetStatusMessage(); msg != "" {
+		return msg
+	}
+	counts := fmt.Sprintf("%d items", totalItems)
+	if markedCount := len(navigator.GetMarkedItems()); markedCount > 0 {
+		counts += fmt.Sprintf(", %d marked", markedCount)
+	}
+	if hiddenCount := navigator.HiddenCount(); hiddenCount > 0 {
+		counts += fmt.Sprintf(", %d hidden shown", hiddenCount)
+	}
+	if mode := navigator.GetConfig().SortMode; mode != SortByName {
+		counts += ", by " + mode.String()
+	}
+	return fmt.Sprintf("[%s] • %s", counts, hints)
+}
+
+// Mode identifies which keys are active, so the status bar can hint at them.
+type Mode int
+
+const (
+	ModeNormal Mode = iota
+	ModePick
+	ModeFlat
+	ModeSearch
+	ModeHighlight
+	ModePrompt
+	ModeConfirm
+	ModeDetails
+	ModeMessages
+	ModePreview
+)
+
+// modeHints lists the most useful keys for each mode.
+var modeHints = map[Mode]string{
+	ModeNormal:    "↑↓ navigate • Enter open • o open in terminal • q quit • / search",
+	ModePick:      "↑↓ navigate • Enter pick file / open dir • . pick this directory • q cancel",
+	ModeFlat:      "↑↓ navigate • Enter reveal • F leave flat view • / search • q quit",
+	ModeSearch:    "Esc done • Tab highlight • Ctrl-P paths",
+	ModeHighlight: "↑↓ jump to match • Tab filter • Ctrl-P paths • Esc done",
+	ModePrompt:    "Enter confirm • Esc cancel",
+	ModeConfirm:   "y then Enter to confirm • Esc cancel",
+	ModeDetails:   "Esc close • # inode/device numbers",
+	ModeMessages:  "Esc close",
+	ModePreview:   "↑↓ move • v start range • y copy lines • Esc back",
+}
+
+// currentMode returns the mode that receives key presses, matching the order
+// the main loop dispatches them in.
+func currentMode(navigator *Navigator) Mode {
+	if prompt := navigator.GetPrompt(); prompt != nil {
+		if prompt.Confirm {
+			return ModeConfirm
+		}
+		return ModePrompt
+	}
+	if navigator.IsMessagesOpen() {
+		return ModeMessages
+	}
+	if navigator.IsDetailsOpen() {
+		return ModeDetails
+	}
+	if navigator.IsPreviewFocused() {
+		return ModePreview
+	}
 	if navigator.GetSearchMode() {
-		return fmt.Sprintf("Search: %s", navigator.GetSearchTerm())
+		if navigator.GetConfig().SearchHighlight {
+			return ModeHighlight
+		}
+		return ModeSearch
+	}
+	if flat, _ := navigator.IsFlatView(); flat {
+		return ModeFlat
+	}
+	if navigator.GetConfig().Pick {
+		return ModePick
 	}
-	return fmt.Sprintf("[%d items] • ↑↓ navigate • Enter open • o open in terminal • q quit • / search", totalItems)
+	return ModeNormal
 }
 
 // drawText draws text at the specified position.
-func drawText(screen tcell.Screen, x, y int, style tcell.Style, text string) {
+func drawText(screen tcell.Screen, x, y int, style tcell.Style, text string, glyphs Glyphs) {
 	w, _ := screen.Size()
-	
+	drawTextIn(screen, x, y, w, style, text, glyphs)
+}
+
+// drawTextIn draws text at the specified position, truncated to end before column w.
+func drawTextIn(screen tcell.Screen, x, y, w int, style tcell.Style, text string, glyphs Glyphs) {
 	// Smart truncation for long text
 	if len(text) > w-x {
-		text = truncateFilename(text, w-x-1)
+		text = truncateFilename(text, w-x-1, glyphs.Ellipsis)
 	}
 	
 	for i, r := range []rune(text) {
@@ -195,15 +1428,56 @@ func drawText(screen tcell.Screen, x, y int, style tcell.Style, text string) {
 	}
 }
 
-// truncateFilename intelligently truncates long filenames
-func truncateFilename(filename string, maxLen int) string {
-	if len(filename) <= maxLen {
+// itemDisplayName formats an item's name for the listing, with a trailing
+// slash after directories when dirSlash is set and the special file indicator.
+func itemDisplayName(item FileItem, dirSlash bool) string {
+	displayName := displayNameSafe(item.Name)
+	if dirSlash && item.IsDir && !item.IsParent {
+		displayName += "/"
+	}
+	return displayName + item.Type.Indicator()
+}
+
+// displayNameSafe makes a filename safe to draw: control characters are shown
+// in caret notation (a tab becomes ^I) and a name with trailing spaces is
+// quoted so the spaces stay visible.
+func displayNameSafe(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r < 0x20:
+			b.WriteByte('^')
+			b.WriteRune(r + '@')
+		case r == 0x7f:
+			b.WriteString("^?")
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, "\\u%04x", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	safe := b.String()
+	if strings.HasSuffix(safe, " ") {
+		safe = `"` + safe + `"`
+	}
+	return safe
+}
+
+// truncateFilename intelligently truncates long filenames to maxLen terminal
+// columns, measuring wide and combining characters as they are displayed
+func truncateFilename(filename string, maxLen int, ellipsis string) string {
+	if runewidth.StringWidth(filename) <= maxLen {
 		return filename
 	}
+	ellipsisLen := runewidth.StringWidth(ellipsis)
+	if maxLen < ellipsisLen {
+		return ""
+	}
 	
 	// If it's too short to truncate meaningfully, just use ellipsis
 	if maxLen < 10 {
-		return filename[:maxLen-1] + "…"
+		return runewidth.Truncate(filename, maxLen, ellipsis)
 	}
 	
 	// For filenames with extensions, try to preserve the extension
@@ -214,17 +1488,17 @@ func truncateFilename(filename string, maxLen int) string {
 			nameWithoutExt := strings.Join(parts[:len(parts)-1], ".")
 			
 			// If extension is reasonable length, preserve it
-			if len(ext) <= maxLen/3 {
-				availableForName := maxLen - len(ext) - 1 // -1 for ellipsis
+			if extLen := runewidth.StringWidth(ext); extLen <= maxLen/3 {
+				availableForName := maxLen - extLen - ellipsisLen
 				if availableForName > 0 {
-					return nameWithoutExt[:availableForName] + "…" + ext
+					return runewidth.Truncate(nameWithoutExt, availableForName, "") + ellipsis + ext
 				}
 			}
 		}
 	}
 	
 	// Default truncation
-	return filename[:maxLen-1] + "…"
+	return runewidth.Truncate(filename, maxLen, ellipsis)
 }
 
 // showHelp displays help information.
@@ -232,14 +1506,84 @@ func showHelp() {
 	fmt.Print(`nav - Terminal File Navigator
 
 USAGE:
-  nav [directory]     Navigate to directory (default: current directory)
+  nav [directory]     Navigate to directory (default: the directory pinned
+                      with P, or the current directory)
+  nav sftp://[user@]host[:port]/path
+                      Browse a remote directory read-only over ssh
+  nav --ascii         Draw the tree and truncation with ASCII characters only
+  nav --exit-on GLOB  Exit and print the path when entering a matching directory
+  nav --output PATH   Append the selected path to PATH (file or FIFO) with t
+  nav --no-exec       Never start terminals, apps, editors or commands
+  nav --pick          Pick a path for a script: Enter on a file, or . in a
+                      directory, exits and prints it; q exits with status 1
+  nav --edit-config   Open the config file in $VISUAL or $EDITOR, creating it
+                      from a commented template when missing
   nav --help, -h      Show this help
 
 KEYBINDINGS:
   ↑/↓        Navigate up/down
-  Enter      Open directory / Open file's parent in terminal
+  Enter      Open directory / Run the file's enter rule (default: parent in terminal)
+             On macOS, bundles such as Foo.app open in their app; Alt-Enter enters them
+             In the flat view, Enter goes to the file's directory and selects it;
+             Alt-Enter opens the file where it is
+  Bksp / h   Go to parent directory
+  Alt-← / →  Go back/forward through visited directories
+  → / ←      Expand the selected directory in place / collapse it (or the one
+             holding the selected entry)
+  Tab        Switch to the previous directory (like cd -)
+  s          Cycle sort mode (name, extension, unsorted) and show the new mode
+  A          Toggle sorting names ignoring case (apple before Zebra)
+  t          Send selected path to the --output target and keep browsing
+  y          Copy selected path relative to the start dir, project root or a path
+  Y          Copy the current directory's path
+  K          Copy the paths of the marked items, one per line
+  T          Copy a tree of the current directory (3 levels deep) as text
+  W          Write that tree as a Markdown nested list to the clipboard or a file
+  b          Stash: copy the selected item into stash_dir
+  P          Pin the current directory as the start for launches without a path
+  R          Make the selected directory the session root (going up stops
+             there); press again to clear it
+  g          Go to a path (end with / to require a directory)
+  ~          Go to the home directory
+  ` + "`" + `          Go to the filesystem root
+  =          Go back to the directory nav was started in
   o          Open selected item in new terminal
-  /          Search (type to filter, Esc to exit)
+  O          Open a terminal for each marked directory
+  N          Open another nav in a new terminal at the selected directory
+  [ / ]      Jump to previous/next sibling directory
+  z / Z      Jump to next/previous hidden entry
+  x          Toggle showing only files with the selected file's extension
+  /          Search (type to filter, Ctrl-P to match paths, Esc to exit)
+             Tab switches to highlighting matches; ↑/↓ then jump between them
+  M          Toggle mouse hover selection
+  @          Toggle showing the real path of a directory reached via a symlink
+  F          Toggle a flat list of every file below the current directory
+  B          Toggle the size column between apparent size and size on disk
+  \          Toggle the trailing / after directory names
+  f          Toggle whether Enter follows symlinked directories
+  d          Toggle showing the size and age after each name
+  L          Switch between names only and the long view (perms, size, date)
+  V          Remember the sort order and columns for this directory (again to forget)
+  p          Toggle preview pane
+  w          Toggle wrapping long lines in the preview
+  v          Move into the preview to copy lines: ↑/↓ move, v starts a range,
+             y or Enter copies it, Esc goes back
+  < / >      Shrink/grow the list against the preview pane
+  I          Toggle image previews (kitty graphics terminals)
+  i          Show details of the selected item (# toggles inode/device numbers)
+  Space      Mark/unmark selected item
+  + / -      Mark/unmark visible items matching a glob (e.g. *.tmp)
+  a          Mark every visible item
+  u          Unmark everything
+  S          Show the total size of the marked items
+  r          Rename selected item, with the cursor before the extension
+             (←/→, Home and End move it)
+  n          Create a new empty file
+  m          Move marked items (or selected item) to a directory
+  c / C      Chmod marked items (or selected item); C recurses into directories
+  .          With --pick, exit and print the current directory
+  H          Show recent messages and errors
+  E          Edit the config file (created from a template when missing)
   q          Quit
 
 TERMINAL DETECTION:
@@ -253,6 +1597,83 @@ TERMINAL DETECTION:
     export TERMINAL="wezterm start --cwd"
     export TERMINAL="alacritty --working-directory"
 
+CONFIGURATION:
+  Settings are read from $NAV_CONFIG, or nav/config in your user config
+  directory, one "key = value" per line (# starts a comment):
+    ascii = true           ASCII-only tree and ellipsis glyphs
+    wrap_siblings = true   [ and ] wrap around at the first/last sibling
+    wrap_hidden = true     z and Z wrap around at the last/first hidden entry
+    mouse_hover = true     Moving the mouse selects, clicking opens
+    exit_pattern = wt-*    Same as --exit-on
+    no_exec = true         Same as --no-exec
+    max_terminals = 5      Ask before O opens more terminals than this
+    history_size = 100     Visited directories kept for Alt-← / → (0 = no limit)
+    big_dir_entries = 5000 Flag directories with more entries and ask before
+                           entering them (0 = off)
+    show_mounts = true     Flag directories that are mount points with [mount]
+    one_file_system = true S, T and copies skip other filesystems, like du -x
+    max_depth = 256        Levels copy, move, chmod and S descend
+                           before failing (0 = no limit)
+    preserve_attributes = false Give copies default permissions and the
+                           current time instead of the source's, like cp without -p
+    stash_dir = ~/stash    Where b copies the selected item, created when missing
+    on_collision = ask     What m and b do about a name already in the destination:
+                           refuse (default), skip, overwrite, rename (adds " (1)")
+                           or ask each time
+    hide_parent = true     Omit the ../ entry (use Backspace or h to go up)
+    parent_label = ..      Label for the ../ entry; {name} is the parent's name
+    select_first_entry = true Start the selection past ../
+    top_goes_up = true     Up on the first entry goes to the parent directory
+    bottom_enters = true   Down on a directory that is the last entry enters it
+    remember_view = true   Return to each directory with its selection and scroll
+    rename_stem = true     Rename edits only the stem and keeps the extension
+    copy_separator = null  Join the paths K copies with NUL, for xargs -0
+    refresh_interval = 5   Rescan the directory every N seconds (0 = off)
+    sort = extension       Initial sort mode: name, extension or unsorted
+    sort_ignore_case = true Sort names ignoring case, like a dictionary
+    group_symlinks = true  Sort symlinks to directories with the directories
+    follow_symlinks = false Enter refuses symlinked directories (toggled with f)
+    skip_single_child = true Enter descends through directories holding
+                           nothing but one subdirectory, like src/main/java/com
+    scroll_hints = true    Show how many entries are above and below the list
+    search_paths = true    Search matches relative paths, not just names
+    search_highlight = true Search highlights matches instead of filtering
+    show_real_path = true  Show where a symlinked current directory resolves to
+    preview = true         Show the preview pane on startup
+    preview_wrap = false   Clip long preview lines instead of wrapping
+    preview_images = false Show text instead of images in the preview
+    tab_width = 4          Columns per tab stop in the preview
+    preview_split = 50     Percent of the width given to the list (20-80)
+    preview_max_size = 1M  Skip previewing larger files (K, M, G; 0 = no limit)
+    dir_slash = false      Leave out the trailing / after directory names
+    compact_details = true Show the size and age after each name
+    show_git = true        Inside a git repository, show each entry's status (M, A,
+                           ?, R, D, U) before its name, updated on every rescan;
+                           git runs in the background, never with --no-exec
+    show_perms = true      Show permissions, size and modification date
+    show_size = true         columns before each name
+    show_date = true
+    size_on_disk = true    Size column shows allocated blocks (Unix)
+    details_ids = true     Details show inode, device and link count (Unix)
+    column_padding = 2     Spaces between columns
+    column_separator = |   Character drawn between columns
+    date_format = relative Date column: iso, relative, or a Go time layout
+    selection_style = reverse Selected row style: default, high-contrast or
+                           reverse (or set $NAV_SELECTION_STYLE)
+    selection_fg = black   Selected row colors, by name or #rrggbb
+    selection_bg = #ffcc00
+    op_log = ~/nav-ops.log Append creates, renames, moves, copies and chmods to this
+                           file as JSON lines (or set $NAV_OP_LOG)
+    on_select = cmd        Run cmd with the selected path whenever the selection
+                           settles (e.g. to update a preview elsewhere)
+    enter_rules = md:edit, image/*:open, sh:run
+                           What Enter does for files, by extension, mime type
+                           or mime class: edit, open, run, preview, terminal
+    command.gitlog = git log -- {path}
+    bind.G = gitlog        Run a shell command in the current directory with a
+                           key nav leaves free; {path}, {name} and {dir} are
+                           replaced by the selection, its name and the directory
+
 FEATURES:
   • Smart terminal detection
   • Real-time search filtering
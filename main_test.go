@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestDisplayNameSafe(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"plain.txt", "plain.txt"},
+		{"tab\there", "tab^Ihere"},
+		{"line\nbreak", "line^Jbreak"},
+		{"bell\a", "bell^G"},
+		{"del\x7f", "del^?"},
+		{"c1\u0085", `c1\u0085`},
+		{"trailing  ", `"trailing  "`},
+		{" leading", " leading"},
+		{"inner space", "inner space"},
+		{"日本語", "日本語"},
+	}
+	for _, tt := range tests {
+		if got := displayNameSafe(tt.name); got != tt.want {
+			t.Errorf("displayNameSafe(%q) expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestItemDisplayName(t *testing.T) {
+	dir := FileItem{Name: "src", IsDir: true, Type: TypeDir}
+	file := FileItem{Name: "main.go", Type: TypeRegular}
+	parent := FileItem{Name: "../", IsDir: true, IsParent: true, Type: TypeDir}
+
+	tests := []struct {
+		item     FileItem
+		dirSlash bool
+		want     string
+	}{
+		{dir, true, "src/"},
+		{dir, false, "src"},
+		{file, true, "main.go"},
+		{file, false, "main.go"},
+		{parent, true, "../"},
+		{parent, false, "../"},
+	}
+	for _, tt := range tests {
+		if got := itemDisplayName(tt.item, tt.dirSlash); got != tt.want {
+			t.Errorf("itemDisplayName(%q, %v) expected %q, got %q", tt.item.Name, tt.dirSlash, tt.want, got)
+		}
+	}
+}
+
+func TestTruncateFilenameGlyphs(t *testing.T) {
+	name := "a_very_long_filename_that_needs_truncating.txt"
+
+	unicodeResult := truncateFilename(name, 20, unicodeGlyphs.Ellipsis)
+	if !strings.Contains(unicodeResult, "…") || !strings.HasSuffix(unicodeResult, ".txt") {
+		t.Errorf("Unicode truncation expected ellipsis and extension, got %q", unicodeResult)
+	}
+
+	asciiResult := truncateFilename(name, 20, asciiGlyphs.Ellipsis)
+	if strings.Contains(asciiResult, "…") || !strings.Contains(asciiResult, "...") {
+		t.Errorf("ASCII truncation expected \"...\", got %q", asciiResult)
+	}
+	if len(asciiResult) != 20 {
+		t.Errorf("ASCII truncation expected length 20, got %d (%q)", len(asciiResult), asciiResult)
+	}
+	if !strings.HasSuffix(asciiResult, ".txt") {
+		t.Errorf("ASCII truncation should preserve extension, got %q", asciiResult)
+	}
+
+	// Short limits fall back to plain truncation
+	shortResult := truncateFilename(name, 8, asciiGlyphs.Ellipsis)
+	if shortResult != "a_ver..." {
+		t.Errorf("Short ASCII truncation expected %q, got %q", "a_ver...", shortResult)
+	}
+
+	// Widths are display columns, and characters are never cut in half
+	if got := truncateFilename("résumé.pdf", 10, asciiGlyphs.Ellipsis); got != "résumé.pdf" {
+		t.Errorf("Expected a name exactly as wide as the limit kept, got %q", got)
+	}
+	if got := truncateFilename("日本語のファイル名.txt", 8, asciiGlyphs.Ellipsis); got != "日本..." {
+		t.Errorf("Short wide truncation expected %q, got %q", "日本...", got)
+	}
+}
+
+func TestTreePrefixGlyphs(t *testing.T) {
+	if got := treePrefix(false, glyphsFor(false)); got != "├── " {
+		t.Errorf("Unicode branch prefix expected %q, got %q", "├── ", got)
+	}
+	if got := treePrefix(true, glyphsFor(false)); got != "└── " {
+		t.Errorf("Unicode last prefix expected %q, got %q", "└── ", got)
+	}
+	if got := treePrefix(false, glyphsFor(true)); got != "|-- " {
+		t.Errorf("ASCII branch prefix expected %q, got %q", "|-- ", got)
+	}
+	if got := treePrefix(true, glyphsFor(true)); got != "`-- " {
+		t.Errorf("ASCII last prefix expected %q, got %q", "`-- ", got)
+	}
+}
+
+func TestItemIndexAtRow(t *testing.T) {
+	screenHeight := 10 // Rows 2..7 hold items
+	tests := []struct {
+		y, totalItems, want int
+	}{
+		{0, 5, -1}, // Path line
+		{1, 5, -1}, // Blank line under the path
+		{2, 5, 0},
+		{4, 5, 2},
+		{6, 5, 4},
+		{7, 5, -1}, // Past the last item
+		{7, 20, 5},
+		{8, 20, -1}, // Reserved above the status bar
+		{9, 20, -1}, // Status bar
+	}
+	for _, tt := range tests {
+		if got := itemIndexAtRow(tt.y, screenHeight, tt.totalItems, 0); got != tt.want {
+			t.Errorf("itemIndexAtRow(%d, %d, %d) = %d, want %d", tt.y, screenHeight, tt.totalItems, got, tt.want)
+		}
+	}
+
+	// Scrolled lists map rows past the offset
+	if got := itemIndexAtRow(2, screenHeight, 20, 10); got != 10 {
+		t.Errorf("itemIndexAtRow with offset 10 expected 10, got %d", got)
+	}
+	if got := itemIndexAtRow(7, screenHeight, 12, 10); got != -1 {
+		t.Errorf("itemIndexAtRow past the end of a scrolled list expected -1, got %d", got)
+	}
+}
+
+func TestScrollbarThumb(t *testing.T) {
+	tests := []struct {
+		total, visible, offset int
+		start, size            int
+		ok                     bool
+	}{
+		{5, 10, 0, 0, 0, false},  // Everything fits
+		{10, 10, 0, 0, 0, false}, // Exactly fits
+		{20, 10, 0, 0, 5, true},  // Half visible, at the top
+		{20, 10, 10, 5, 5, true}, // At the bottom
+		{20, 10, 5, 2, 5, true},  // Halfway
+		{1000, 10, 0, 0, 1, true},
+		{1000, 10, 990, 9, 1, true},  // Thumb never shrinks below one row
+		{1000, 10, 2000, 9, 1, true}, // Offsets past the end are clamped
+	}
+	for _, tt := range tests {
+		start, size, ok := scrollbarThumb(tt.total, tt.visible, tt.offset, tt.visible)
+		if start != tt.start || size != tt.size || ok != tt.ok {
+			t.Errorf("scrollbarThumb(%d, %d, %d) = (%d, %d, %v), want (%d, %d, %v)",
+				tt.total, tt.visible, tt.offset, start, size, ok, tt.start, tt.size, tt.ok)
+		}
+	}
+}
+
+func TestScrollHints(t *testing.T) {
+	tests := []struct {
+		total, visible, offset int
+		above, below           int
+	}{
+		{5, 10, 0, 0, 0},    // Everything fits
+		{30, 10, 0, 0, 20},  // At the top only more below
+		{30, 10, 8, 8, 12},  // In the middle both
+		{30, 10, 20, 20, 0}, // At the bottom only more above
+		{30, 0, 5, 0, 0},    // No rows to show
+	}
+	for _, tt := range tests {
+		above, below := scrollHints(tt.total, tt.visible, tt.offset)
+		if above != tt.above || below != tt.below {
+			t.Errorf("scrollHints(%d, %d, %d) = (%d, %d), want (%d, %d)",
+				tt.total, tt.visible, tt.offset, above, below, tt.above, tt.below)
+		}
+	}
+}
+
+func TestStatusBarHintsFollowMode(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	nav.ScanDirectory()
+
+	check := func(mode Mode, wantHint string) {
+		t.Helper()
+		if got := currentMode(nav); got != mode {
+			t.Errorf("Expected mode %d, got %d", mode, got)
+		}
+		if bar := buildStatusBar(nav, 0); !strings.HasSuffix(bar, wantHint) {
+			t.Errorf("Expected status bar to end with %q, got %q", wantHint, bar)
+		}
+	}
+
+	check(ModeNormal, modeHints[ModeNormal])
+
+	nav.ToggleFlatView()
+	check(ModeFlat, "F leave flat view • / search • q quit")
+	nav.ToggleFlatView()
+
+	nav.ToggleSearchMode()
+	check(ModeSearch, "Esc done • Tab highlight • Ctrl-P paths")
+	nav.ToggleSearchHighlight()
+	check(ModeHighlight, "↑↓ jump to match • Tab filter • Ctrl-P paths • Esc done")
+	nav.ToggleSearchMode()
+
+	nav.StartPrompt("Go to: ", "", func(string) error { return nil })
+	check(ModePrompt, "Enter confirm • Esc cancel")
+	nav.CancelPrompt()
+
+	startConfirmPrompt(nav, "Really?", func() error { return nil })
+	check(ModeConfirm, "y then Enter to confirm • Esc cancel")
+}
+
+func TestAltEnterConfirmsBigDir(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "dir")
+	os.Mkdir(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "a"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "b"), nil, 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.BigDirEntries = 1
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	selectByName(t, nav, "dir")
+
+	handleNormalModeKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModAlt), nav)
+	if prompt := nav.GetPrompt(); prompt == nil || !prompt.Confirm {
+		t.Fatalf("Expected Alt-Enter to ask before entering a big directory, got %+v", prompt)
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected to stay in %s until confirmed, got %s", tempDir, nav.GetCurrentPath())
+	}
+	nav.SetPromptText("y")
+	if err := nav.SubmitPrompt(); err != nil {
+		t.Fatalf("Confirming failed: %v", err)
+	}
+	if nav.GetCurrentPath() != dir {
+		t.Errorf("Expected to enter %s once confirmed, got %s", dir, nav.GetCurrentPath())
+	}
+}
+
+func TestTreePrefixes(t *testing.T) {
+	items := []FileItem{
+		{Name: "docs"},
+		{Name: "guide.md", Depth: 1},
+		{Name: "src"},
+		{Name: "lib", Depth: 1},
+		{Name: "util.go", Depth: 2},
+		{Name: "main.go", Depth: 1},
+		{Name: "readme.md"},
+	}
+	want := []string{
+		"|-- ",
+		"|   `-- ",
+		"|-- ",
+		"|   |-- ",
+		"|   |   `-- ",
+		"|   `-- ",
+		"`-- ",
+	}
+	if got := treePrefixes(items, asciiGlyphs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Without expanded directories only the last entry differs
+	flat := []FileItem{{Name: "a"}, {Name: "b"}}
+	if got := treePrefixes(flat, asciiGlyphs); !reflect.DeepEqual(got, []string{"|-- ", "`-- "}) {
+		t.Errorf("Expected a plain list, got %q", got)
+	}
+}
+
+func TestQuitStatus(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	if code := quitStatus(nav); code != 0 {
+		t.Errorf("Expected status 0 for a plain quit, got %d", code)
+	}
+	cfg := nav.GetConfig()
+	cfg.Pick = true
+	nav.SetConfig(cfg)
+	if code := quitStatus(nav); code != 1 {
+		t.Errorf("Expected status 1 when --pick quits without a choice, got %d", code)
+	}
+}
+
+func TestPreviewImageHiddenUnderPopups(t *testing.T) {
+	tempDir := t.TempDir()
+	var data bytes.Buffer
+	png.Encode(&data, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	os.WriteFile(filepath.Join(tempDir, "a.png"), data.Bytes(), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.Preview = true
+	nav.SetConfig(cfg)
+	nav.SetImageProtocol(ImageKitty)
+	nav.ScanDirectory()
+	selectByName(t, nav, "a.png")
+	if previewImageKey(nav, 80, 24) == "" {
+		t.Fatal("Expected the image to be shown")
+	}
+
+	for _, popup := range []struct {
+		name   string
+		toggle func()
+	}{
+		{"details", nav.ToggleDetails},
+		{"messages", nav.ToggleMessages},
+		{"prompt", func() {
+			if nav.GetPrompt() == nil {
+				nav.StartPrompt("Name: ", "", func(string) error { return nil })
+			} else {
+				nav.CancelPrompt()
+			}
+		}},
+	} {
+		popup.toggle()
+		if key := previewImageKey(nav, 80, 24); key != "" {
+			t.Errorf("Expected no image while the %s popup is open, got %q", popup.name, key)
+		}
+		popup.toggle()
+		if previewImageKey(nav, 80, 24) == "" {
+			t.Errorf("Expected the image back once the %s popup closes", popup.name)
+		}
+	}
+}
+
+func TestScrollHintKeepsRealPath(t *testing.T) {
+	tempDir := t.TempDir()
+	real := filepath.Join(tempDir, "real")
+	os.Mkdir(real, 0755)
+	for i := 0; i < 20; i++ {
+		os.WriteFile(filepath.Join(real, fmt.Sprintf("file%02d", i)), nil, 0644)
+	}
+	link := filepath.Join(tempDir, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	nav, _ := NewNavigator(link)
+	cfg := nav.GetConfig()
+	cfg.ShowRealPath, cfg.ScrollHints, cfg.ASCII = true, true, true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	if nav.GetRealPath() == "" {
+		t.Skip("no real path for the symlinked directory")
+	}
+	selectByName(t, nav, "file19")
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(200, 10)
+	drawUI(screen, nav, tcell.StyleDefault)
+	row := func(y int) string {
+		var sb strings.Builder
+		for x := 0; x < 200; x++ {
+			r, _, _, _ := screen.GetContent(x, y)
+			sb.WriteRune(r)
+		}
+		return strings.TrimSpace(sb.String())
+	}
+
+	if got := row(1); got != "-> "+nav.GetRealPath() {
+		t.Errorf("Expected the real path line intact, got %q", got)
+	}
+	if got := row(0); !strings.Contains(got, "^ ") || !strings.Contains(got, " more") {
+		t.Errorf("Expected the hint above in the header, got %q", got)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import "time"
+
+// maxMessages is how many status messages are kept for the message history.
+const maxMessages = 100
+
+// loggedMessage is a status message and when it was shown.
+type loggedMessage struct {
+	At   time.Time
+	Text string
+}
+
+// messageLog is a ring buffer holding the most recent status messages, so
+// ones that vanished from the status bar can be looked at again.
+type messageLog struct {
+	entries []loggedMessage
+	next    int // Where the next message goes once the buffer is full
+	limit   int
+}
+
+// add records msg, dropping the oldest message when the log is full.
+func (l *messageLog) add(msg loggedMessage) {
+	if l.limit <= 0 {
+		return
+	}
+	if len(l.entries) < l.limit {
+		l.entries = append(l.entries, msg)
+		return
+	}
+	l.entries[l.next] = msg
+	l.next = (l.next + 1) % l.limit
+}
+
+// list returns the recorded messages, oldest first.
+func (l *messageLog) list() []loggedMessage {
+	list := make([]loggedMessage, 0, len(l.entries))
+	list = append(list, l.entries[l.next:]...)
+	return append(list, l.entries[:l.next]...)
+}
+
+// ToggleMessages opens or closes the message history popup.
+func (n *Navigator) ToggleMessages() {
+	n.messagesOpen = !n.messagesOpen
+}
+
+// IsMessagesOpen reports whether the message history popup is shown.
+func (n *Navigator) IsMessagesOpen() bool {
+	return n.messagesOpen
+}
+
+// MessageLines returns the message history as lines for the popup, oldest
+// first, each prefixed with the time it was shown.
+func (n *Navigator) MessageLines() []string {
+	messages := n.messages.list()
+	if len(messages) == 0 {
+		return []string{"No messages yet"}
+	}
+	lines := make([]string, len(messages))
+	for i, msg := range messages {
+		lines[i] = msg.At.Format("15:04:05") + "  " + msg.Text
+	}
+	return lines
+}
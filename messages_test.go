@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// messageTexts returns the text of each message in order.
+func messageTexts(messages []loggedMessage) []string {
+	texts := make([]string, len(messages))
+	for i, msg := range messages {
+		texts[i] = msg.Text
+	}
+	return texts
+}
+
+func TestMessageLog(t *testing.T) {
+	log := messageLog{limit: 3}
+	if got := log.list(); len(got) != 0 {
+		t.Errorf("Expected an empty log, got %v", got)
+	}
+
+	for i := 1; i <= 2; i++ {
+		log.add(loggedMessage{Text: fmt.Sprint(i)})
+	}
+	if got := messageTexts(log.list()); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("Expected [1 2] before filling up, got %v", got)
+	}
+
+	// Past the capacity the oldest messages make way, and order is kept
+	for i := 3; i <= 7; i++ {
+		log.add(loggedMessage{Text: fmt.Sprint(i)})
+	}
+	if got := messageTexts(log.list()); !reflect.DeepEqual(got, []string{"5", "6", "7"}) {
+		t.Errorf("Expected the newest three [5 6 7], got %v", got)
+	}
+	if len(log.entries) != 3 {
+		t.Errorf("Expected the log to stay at 3 entries, got %d", len(log.entries))
+	}
+}
+
+func TestStatusMessagesAreLogged(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	if got := nav.MessageLines(); !reflect.DeepEqual(got, []string{"No messages yet"}) {
+		t.Errorf("Expected a placeholder without messages, got %v", got)
+	}
+
+	nav.SetStatusMessage("Copied 2 paths")
+	nav.SetStatusMessage("") // Clearing the status bar is not a message
+	nav.SetStatusMessage("Error opening terminal: not found")
+
+	lines := nav.MessageLines()
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 logged messages, got %v", lines)
+	}
+	if !strings.HasSuffix(lines[0], "  Copied 2 paths") || !strings.HasSuffix(lines[1], "  Error opening terminal: not found") {
+		t.Errorf("Expected the messages oldest first, got %v", lines)
+	}
+	if _, err := time.Parse("15:04:05", strings.Fields(lines[0])[0]); err != nil {
+		t.Errorf("Expected each line to start with the time, got %q", lines[0])
+	}
+}
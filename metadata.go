@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// itemMeta is the part of an item that takes a stat to learn.
+type itemMeta struct {
+	size     int64
+	diskSize int64
+	modTime  time.Time
+	mode     os.FileMode
+}
+
+// needsMetadata reports whether every listed item's size, date or mode is
+// shown, in columns or the compact view. Sorting only looks at names and
+// types, so otherwise a scan costs a single ReadDir and no stat per entry.
+func needsMetadata(cfg Config) bool {
+	return cfg.ShowPerms || cfg.ShowSize || cfg.ShowDate || cfg.CompactDetails
+}
+
+// statItem loads the metadata of an item listed from a directory entry.
+// Metadata is left zero for entries that cannot be stat'ed.
+func statItem(item FileItem) itemMeta {
+	meta := itemMeta{diskSize: -1}
+	info, err := item.entry.Info()
+	if err != nil {
+		return meta
+	}
+	meta.size = info.Size()
+	if size, ok := diskSize(info); ok {
+		meta.diskSize = size
+	}
+	meta.modTime = info.ModTime()
+	meta.mode = info.Mode()
+	return meta
+}
+
+// withMetadata returns item with its size, date and mode filled in. Entries
+// are stat'ed at most once per scan; the results are cached by path until the
+// directory is read again.
+func (n *Navigator) withMetadata(item FileItem) FileItem {
+	if item.entry == nil {
+		return item
+	}
+	meta, ok := n.metaCache[item.Path]
+	if !ok {
+		meta = statItem(item)
+		if n.metaCache == nil {
+			n.metaCache = make(map[string]itemMeta)
+		}
+		n.metaCache[item.Path] = meta
+	}
+	item.Size, item.DiskSize, item.ModTime, item.Mode = meta.size, meta.diskSize, meta.modTime, meta.mode
+	item.entry = nil
+	return item
+}
+
+// loadMetadata fills in the metadata of every listed item when a column
+// needs it.
+func (n *Navigator) loadMetadata() {
+	if !needsMetadata(n.config) {
+		return
+	}
+	for i := range n.items {
+		n.items[i] = n.withMetadata(n.items[i])
+	}
+	// A filtering search holds copies of the items
+	for i := range n.filteredItems {
+		n.filteredItems[i] = n.withMetadata(n.filteredItems[i])
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// countingEntry counts how often its metadata is asked for.
+type countingEntry struct {
+	fs.DirEntry
+	infos *int
+}
+
+func (e countingEntry) Info() (fs.FileInfo, error) {
+	*e.infos++
+	return e.DirEntry.Info()
+}
+
+// countingSource lists a memSource through countingEntry.
+type countingSource struct {
+	memSource
+	infos *int
+}
+
+func (s countingSource) ReadDir(path string) ([]FileItem, error) {
+	name, err := s.name(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		entries[i] = countingEntry{entry, s.infos}
+	}
+	return itemsFromEntries(path, entries), nil
+}
+
+func TestLazyMetadata(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "root")
+	infos := 0
+	source := countingSource{memSource{root: root, fsys: fstest.MapFS{
+		"a.txt": {Data: []byte("hello")},
+		"b.txt": {Data: []byte("hi")},
+	}}, &infos}
+
+	nav, _ := NewNavigator(root)
+	nav.SetSource(source)
+	nav.ScanDirectory()
+	if infos != 0 {
+		t.Errorf("Expected no stat without metadata columns, got %d", infos)
+	}
+
+	// Switching to the long view loads every entry once
+	nav.CycleViewPreset()
+	if infos != 2 {
+		t.Errorf("Expected 2 stats for the long view, got %d", infos)
+	}
+	selectByName(t, nav, "a.txt")
+	if size := nav.GetSelectedItem().Size; size != 5 {
+		t.Errorf("Expected size 5, got %d", size)
+	}
+	nav.CycleViewPreset()
+	nav.CycleViewPreset()
+	if infos != 2 {
+		t.Errorf("Expected cached metadata on the second switch, got %d stats", infos)
+	}
+
+	// A rescan reads the metadata again
+	nav.ScanDirectory()
+	if infos != 4 {
+		t.Errorf("Expected a rescan to stat again, got %d", infos)
+	}
+}
+
+func BenchmarkScanDirectory(b *testing.B) {
+	dir := b.TempDir()
+	for i := 0; i < 10000; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%05d.txt", i)), nil, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, long := range []bool{false, true} {
+		name := "columns-off"
+		if long {
+			name = "columns-on"
+		}
+		b.Run(name, func(b *testing.B) {
+			nav, _ := NewNavigator(dir)
+			cfg := nav.GetConfig()
+			cfg.ShowPerms, cfg.ShowSize, cfg.ShowDate = long, long, long
+			nav.SetConfig(cfg)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := nav.ScanDirectory(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// isMountPoint reports whether path is the root of a filesystem, that is a
+// directory on a different device than its parent. The filesystem root always
+// is one. Where the platform does not provide device numbers it reports false.
+func isMountPoint(path string) (bool, error) {
+	if isRoot(path) {
+		return true, nil
+	}
+	parent := filepath.Dir(filepath.Clean(path))
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
+	}
+	parentInfo, err := os.Stat(parent)
+	if err != nil {
+		return false, err
+	}
+	return onOtherDevice(info, parentInfo), nil
+}
+
+// onOtherDevice reports whether info and parent come from different devices.
+// It reports false when either lacks a device number.
+func onOtherDevice(info, parent fs.FileInfo) bool {
+	_, device, _, ok := fileIDs(info)
+	_, parentDevice, _, parentOK := fileIDs(parent)
+	return deviceBoundary(device, ok, parentDevice, parentOK)
+}
+
+// deviceBoundary reports whether device differs from parentDevice, each
+// flagged by whether the platform reported it.
+func deviceBoundary(device uint64, ok bool, parentDevice uint64, parentOK bool) bool {
+	return ok && parentOK && device != parentDevice
+}
+
+// markMountPoints flags the directories among items, the entries of dir, that
+// are mount points.
+func markMountPoints(dir string, items []FileItem) []FileItem {
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return items
+	}
+	for i := range items {
+		if !items[i].IsDir {
+			continue
+		}
+		if info, err := os.Lstat(items[i].Path); err == nil {
+			items[i].MountPoint = onOtherDevice(info, dirInfo)
+		}
+	}
+	return items
+}
+
+// findMountPoint returns the first mount point below root, or "" when root
+// and everything in it are on one filesystem or root is not a directory.
+func findMountPoint(root string, maxDepth int) (string, error) {
+	if info, err := os.Lstat(root); err != nil || !info.IsDir() {
+		return "", err
+	}
+	var mount string
+	err := walkTree(osSource{mounts: true}, root, maxDepth, func(entry walkEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.MountPoint {
+			mount = entry.Path
+			return errStopWalk
+		}
+		return nil
+	})
+	return mount, err
+}
@@ -1,58 +1,146 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"sort"
 	"strings"
+	"time"
 )
 
 // FileItem represents a file or directory entry.
 type FileItem struct {
-	Name     string
-	Path     string
-	IsDir    bool
-	IsHidden bool
+	Name       string
+	Path       string
+	IsDir      bool
+	IsHidden   bool
+	Size       int64
+	DiskSize   int64 // Allocated bytes, or -1 when unknown
+	ModTime    time.Time
+	Mode       os.FileMode
+	Type       FileType
+	LinkDir    bool // Symlink whose target is a directory
+	MountPoint bool // Directory on a different filesystem than its parent
+	IsParent   bool // The entry leading up to the parent directory
+	Depth      int  // Levels below the current directory, inside expanded directories
+
+	entry fs.DirEntry // Set until Size, DiskSize, ModTime and Mode are loaded
+}
+
+// Prompt holds a single-line text input shown in the status bar.
+type Prompt struct {
+	Label   string
+	Text    string
+	Confirm bool // A yes/no question rather than free text
+	Cursor  int  // Rune offset in Text where typing inserts
+	submit  func(text string) error
 }
 
 // Navigator manages the state of the file navigator.
 type Navigator struct {
 	currentPath   string
+	realPath      string
+	cameFrom      string
+	startPath     string
 	items         []FileItem
 	filteredItems []FileItem
 	selectedIdx   int
+	scrollOffset  int
+	flatView      bool
+	flatTruncated bool
+	extFilter     string          // Only files with this extension are listed
+	expanded      map[string]bool // Directories whose contents are listed in place
 	searchMode    bool
 	searchTerm    string
+	config        Config
+	marked        map[string]bool
+	prompt        *Prompt
+	exitPath      string
+	runCommand    func(cmd *exec.Cmd) error
+	runForeground func(cmd *exec.Cmd) error
+	previewPath   string
+	previewLines  []string
+	previewFocus  bool
+	previewCursor int
+	previewAnchor int // Line where the copy range starts, or -1
+	output        io.Writer
+	clipboard     func(text string) error
+	source        FileSource
+	imageProtocol ImageProtocol
+	imagePath     string
+	imageData     []byte
+	detailsOpen   bool
+	messagesOpen  bool
+	messages      messageLog
+	details       *ItemDetails
+	onSelect      *debouncer
+	onSelectPath  string
+	bigDirs       map[string]bigDirCount
+	metaCache     map[string]itemMeta
+	gitCache      map[string]map[string]GitStatus // Git status by path, per directory
+	gitLoads      map[string]gitLoad              // Background git status loads by directory
+	gitLoadCount  int                             // Last token handed out
+	gitStale      map[string]bool                 // Directories whose cached git status predates a refresh
+	deliver       func(apply func())              // Hands background results to the UI, or nil to work synchronously
+	viewStates    map[string]viewState
+	viewsFile     string             // Where directory views are remembered, or ""
+	dirViews      map[string]dirView // Remembered views by directory, once read
+	dirViewPath   string             // Directory whose remembered view was last applied
+	viewBeforeDir *dirView           // View to go back to on leaving remembered directories
+	sessionRoot   string
+	history       history
+	lastDir       string
+	lastSelected  string
+
+	statusMessage   string
+	statusMessageAt time.Time
 }
 
+// statusMessageDuration is how long a status message stays visible.
+const statusMessageDuration = 3 * time.Second
+
 // NewNavigator creates a new Navigator instance.
 func NewNavigator(startPath string) (*Navigator, error) {
-	absPath, err := filepath.Abs(startPath)
+	absPath, err := filepath.Abs(filepath.FromSlash(startPath))
 	if err != nil {
 		return nil, err
 	}
 	return &Navigator{
-		currentPath: absPath,
-		selectedIdx: 0,
+		currentPath:   absPath,
+		startPath:     absPath,
+		selectedIdx:   0,
+		config:        DefaultConfig(),
+		marked:        make(map[string]bool),
+		runCommand:    startCommand,
+		runForeground: runAttached,
+		clipboard:     writeClipboard,
+		source:        osSource{},
+		onSelect:      &debouncer{delay: onSelectDelay},
+		history:       history{entries: []string{absPath}},
+		messages:      messageLog{limit: maxMessages},
 	}, nil
 }
 
 // ScanDirectory reads the contents of the current directory and populates the items slice.
 func (n *Navigator) ScanDirectory() error {
-	entries, err := os.ReadDir(n.currentPath)
+	n.applyDirView()
+	entries, err := n.readDir(n.currentPath)
 	if err != nil {
 		// Check if it's a permission error or other access issue
 		if os.IsPermission(err) {
 			return err // Will be handled by caller with user-friendly message
 		}
 		// Try to handle unrecognized root or other path issues
-		if n.isRootPath(n.currentPath) {
+		if isRoot(n.currentPath) && !n.isRemote() {
 			// If we can't read root, fallback to home directory
-			homeDir, homeErr := os.UserHomeDir()
-			if homeErr == nil {
-				n.currentPath = homeDir
+			fallback, fallbackErr := fallbackDir()
+			if fallbackErr == nil && fallback != n.currentPath {
+				n.currentPath = fallback
 				return n.ScanDirectory()
 			}
 		}
@@ -60,57 +148,119 @@ func (n *Navigator) ScanDirectory() error {
 	}
 
 	n.items = []FileItem{}
+	n.metaCache = nil
+	n.previewPath = ""
+	n.imagePath, n.imageData = "", nil
+	n.details = nil
+
+	// Resolve symlinks once per scan so the real path can be shown. This looks
+	// at the local filesystem, so remote paths are left as they are
+	n.realPath = ""
+	if resolved, err := filepath.EvalSymlinks(n.currentPath); err == nil && resolved != n.currentPath && !n.isRemote() {
+		n.realPath = resolved
+	}
 
-	// Add parent directory if not at root
-	if n.currentPath != "/" && n.currentPath != `C:\` {
+	// Add parent directory if not at root, unless it is hidden by config
+	if !isRoot(n.currentPath) && !n.config.HideParent && n.currentPath != n.sessionRoot {
 		parentPath := filepath.Dir(n.currentPath)
 		n.items = append(n.items, FileItem{
-			Name:     "../",
+			Name:     parentLabel(n.config.ParentLabel, parentPath),
 			Path:     parentPath,
 			IsDir:    true,
 			IsHidden: false,
+			Type:     TypeDir,
+			IsParent: true,
 		})
 	}
 
+	// The flat view lists every file beneath the current directory instead
+	n.flatTruncated = false
+	if n.flatView {
+		var flat []FileItem
+		flat, n.flatTruncated = flatItems(n.source, n.currentPath, maxFlatItems)
+		n.items = append(n.items, flat...)
+		entries = nil // Only the flat list is shown
+	}
+
 	// Add current directory entries
-	for _, entry := range entries {
-		name := entry.Name()
-		fullPath := filepath.Join(n.currentPath, name)
-		isDir := entry.IsDir()
-		isHidden := len(name) > 0 && name[0] == '.'
+	n.items = append(n.items, entries...)
 
-		n.items = append(n.items, FileItem{
-			Name:     name,
-			Path:     fullPath,
-			IsDir:    isDir,
-			IsHidden: isHidden,
-		})
+	n.sortItems()
+	n.filterItems()
+	n.loadMetadata()
+	n.loadGitStatus()
+
+	// Start past "../" when configured; callers restoring a selection override this
+	if n.config.SelectFirstEntry && n.selectedIdx == 0 && len(n.filteredItems) > 1 && n.filteredItems[0].IsParent {
+		n.selectedIdx = 1
 	}
+	return nil
+}
 
-	// Sort items: directories first, then files, both alphabetically
-	sort.Slice(n.items, func(i, j int) bool {
-		itemI := n.items[i]
-		itemJ := n.items[j]
+// parentLabel returns the name shown for the parent entry, replacing {name}
+// in label with the parent directory's name.
+func parentLabel(label, parentPath string) string {
+	if label == "" {
+		return "../"
+	}
+	return strings.ReplaceAll(label, "{name}", filepath.Base(parentPath))
+}
 
-		// Handle "../" always at the top
-		if itemI.Name == "../" {
-			return true
-		}
-		if itemJ.Name == "../" {
-			return false
-		}
+// readDir lists path from the source, in stored order for the unsorted mode
+// when the source supports it.
+func (n *Navigator) readDir(path string) ([]FileItem, error) {
+	if raw, ok := n.source.(rawDirReader); ok && n.config.SortMode == SortUnsorted {
+		return raw.ReadDirRaw(path)
+	}
+	return n.source.ReadDir(path)
+}
 
-		// Directories come before files
-		if itemI.IsDir != itemJ.IsDir {
-			return itemI.IsDir
-		}
+// Refresh rescans the current directory, keeping the selection on the same item.
+// When the selected item is gone, the selection stays at the same position,
+// clamped to the end of the list.
+func (n *Navigator) Refresh() error {
+	var selectedName string
+	selectedIdx := n.selectedIdx
+	if selectedItem := n.GetSelectedItem(); selectedItem != nil {
+		selectedName = selectedItem.Name
+	}
+	n.markGitStale(n.currentPath) // Whatever changed may have changed the status too
+	if err := n.ScanDirectory(); err != nil {
+		return err
+	}
+	if selectedName != "" && !n.selectName(selectedName) {
+		n.selectedIdx = selectedIdx
+		n.clampSelection()
+	}
+	return nil
+}
 
-		// Alphabetical sort within category
-		return itemI.Name < itemJ.Name
-	})
+// clampSelection keeps the selection within the visible items, moving it to
+// the last item when the list shrank below it.
+func (n *Navigator) clampSelection() {
+	if n.selectedIdx >= len(n.filteredItems) {
+		n.selectedIdx = len(n.filteredItems) - 1
+	}
+	if n.selectedIdx < 0 {
+		n.selectedIdx = 0
+	}
+}
 
-	n.filterItems()
-	return nil
+// dropItems removes the items at the given paths from the listing and clamps
+// the selection, so the listing stays consistent until the next rescan.
+func (n *Navigator) dropItems(paths map[string]bool) {
+	keep := func(items []FileItem) []FileItem {
+		kept := make([]FileItem, 0, len(items))
+		for _, item := range items {
+			if !paths[item.Path] {
+				kept = append(kept, item)
+			}
+		}
+		return kept
+	}
+	n.items = keep(n.items)
+	n.filteredItems = keep(n.filteredItems)
+	n.clampSelection()
 }
 
 // GetCurrentPath returns the current directory path.
@@ -118,6 +268,73 @@ func (n *Navigator) GetCurrentPath() string {
 	return n.currentPath
 }
 
+// GetRealPath returns the current directory with symlinks resolved, or "" when
+// it was not reached through a symlink.
+func (n *Navigator) GetRealPath() string {
+	return n.realPath
+}
+
+// GetConfig returns the active settings.
+func (n *Navigator) GetConfig() Config {
+	return n.config
+}
+
+// SetConfig replaces the active settings. A view remembered for the current
+// directory is applied over them on the next scan.
+func (n *Navigator) SetConfig(cfg Config) {
+	n.config = cfg
+	if _, local := n.source.(osSource); local {
+		// Finding mount points stats every directory, so only when they matter
+		n.source = osSource{mounts: cfg.ShowMounts || cfg.OneFileSystem}
+	}
+	n.dirViewPath, n.viewBeforeDir = "", nil
+	n.loadMetadata()
+	n.loadGitStatus()
+}
+
+// ToggleMouseHover toggles whether the mouse pointer selects items without clicking.
+func (n *Navigator) ToggleMouseHover() {
+	n.config.MouseHover = !n.config.MouseHover
+}
+
+// ToggleFollowSymlinks toggles whether Enter descends into symlinked directories.
+func (n *Navigator) ToggleFollowSymlinks() {
+	n.config.FollowSymlinks = !n.config.FollowSymlinks
+}
+
+// ToggleDirSlash toggles the trailing slash after directory names.
+func (n *Navigator) ToggleDirSlash() {
+	n.config.DirSlash = !n.config.DirSlash
+}
+
+// ToggleRealPath toggles showing the resolved path beneath a symlinked current directory.
+func (n *Navigator) ToggleRealPath() {
+	n.config.ShowRealPath = !n.config.ShowRealPath
+}
+
+// SetStatusMessage shows a brief message in the status bar and records it in
+// the message history.
+func (n *Navigator) SetStatusMessage(msg string) {
+	n.statusMessage = msg
+	n.statusMessageAt = time.Now()
+	if msg != "" {
+		n.messages.add(loggedMessage{At: n.statusMessageAt, Text: msg})
+	}
+}
+
+// GetStatusMessage returns the status message, or "" once it has expired.
+func (n *Navigator) GetStatusMessage() string {
+	if time.Since(n.statusMessageAt) > statusMessageDuration {
+		return ""
+	}
+	return n.statusMessage
+}
+
+// GetStatusMessageTime returns when the current status message was set.
+func (n *Navigator) GetStatusMessageTime() time.Time {
+	return n.statusMessageAt
+}
+
 // GetItems returns the filtered items for display.
 func (n *Navigator) GetItems() []FileItem {
 	return n.filteredItems
@@ -128,6 +345,15 @@ func (n *Navigator) GetSelectedIndex() int {
 	return n.selectedIdx
 }
 
+// Position describes where the selection is in the listing, such as "3/57",
+// counting only the items the search leaves visible.
+func (n *Navigator) Position() string {
+	if len(n.filteredItems) == 0 {
+		return "0/0"
+	}
+	return fmt.Sprintf("%d/%d", n.selectedIdx+1, len(n.filteredItems))
+}
+
 // GetSearchMode returns whether search mode is active.
 func (n *Navigator) GetSearchMode() bool {
 	return n.searchMode
@@ -149,6 +375,53 @@ func (n *Navigator) MoveSelection(delta int) {
 	}
 }
 
+// StepSelection moves the selection one row up (delta -1) or down (delta 1)
+// for the arrow keys. With top_goes_up set, Up on the first entry goes to the
+// parent directory, and with bottom_enters set, Down on a directory that is
+// the last entry enters it; otherwise the selection stops at the ends. The
+// mouse wheel and marking use MoveSelection, so they never leave the
+// directory.
+func (n *Navigator) StepSelection(delta int) error {
+	if !n.searchMode && !n.flatView {
+		if delta < 0 && n.selectedIdx == 0 && n.config.TopGoesUp {
+			return n.GoUp()
+		}
+		if delta > 0 && n.selectedIdx == len(n.filteredItems)-1 && n.config.BottomEnters {
+			if selectedItem := n.GetSelectedItem(); selectedItem != nil && !selectedItem.IsParent && (selectedItem.IsDir || selectedItem.LinkDir) {
+				return n.EnterSelected()
+			}
+		}
+	}
+	n.MoveSelection(delta)
+	return nil
+}
+
+// GetScrollOffset returns the index of the first visible item.
+func (n *Navigator) GetScrollOffset() int {
+	return n.scrollOffset
+}
+
+// EnsureVisible adjusts the scroll offset so the selection lies within the
+// given number of visible rows, without scrolling past the end of the list.
+func (n *Navigator) EnsureVisible(rows int) {
+	if rows <= 0 {
+		n.scrollOffset = 0
+		return
+	}
+	if n.selectedIdx < n.scrollOffset {
+		n.scrollOffset = n.selectedIdx
+	}
+	if n.selectedIdx >= n.scrollOffset+rows {
+		n.scrollOffset = n.selectedIdx - rows + 1
+	}
+	if maxOffset := len(n.filteredItems) - rows; n.scrollOffset > maxOffset {
+		n.scrollOffset = maxOffset
+	}
+	if n.scrollOffset < 0 {
+		n.scrollOffset = 0
+	}
+}
+
 // GetSelectedItem returns the currently selected item.
 func (n *Navigator) GetSelectedItem() *FileItem {
 	if len(n.filteredItems) == 0 || n.selectedIdx >= len(n.filteredItems) {
@@ -157,29 +430,381 @@ func (n *Navigator) GetSelectedItem() *FileItem {
 	return &n.filteredItems[n.selectedIdx]
 }
 
+// requireSelection returns the selected item for an action that needs one,
+// or sets a status message and returns nil when the listing is empty.
+func (n *Navigator) requireSelection() *FileItem {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil {
+		n.SetStatusMessage("Nothing selected")
+	}
+	return selectedItem
+}
+
+// execDisabled reports whether starting programs is forbidden by --no-exec,
+// or because the current paths are on a remote source and mean nothing to
+// local programs, and says so in the status bar.
+func (n *Navigator) execDisabled() bool {
+	switch {
+	case n.config.NoExec:
+		n.SetStatusMessage("Running programs is disabled (--no-exec)")
+	case n.isRemote():
+		n.SetStatusMessage("Running programs is disabled while browsing a remote directory")
+	default:
+		return false
+	}
+	return true
+}
+
 // OpenSelected opens the selected item.
 func (n *Navigator) OpenSelected() error {
-	selectedItem := n.GetSelectedItem()
+	selectedItem := n.requireSelection()
 	if selectedItem == nil {
 		return nil
 	}
 
-	if selectedItem.IsDir {
-		// Navigate into directory
-		n.currentPath = selectedItem.Path
-		n.selectedIdx = 0
-		n.searchTerm = ""
-		n.searchMode = false
-		return n.ScanDirectory()
+	if selectedItem.LinkDir && !n.config.FollowSymlinks {
+		n.SetStatusMessage("Not following symlinked directory " + selectedItem.Name + " (f to follow)")
+		return nil
+	}
+
+	// Picking ends at the first file; directories are still entered
+	if n.config.Pick && !selectedItem.IsDir && !selectedItem.LinkDir {
+		n.exitPath = selectedItem.Path
+		return nil
+	}
+
+	if n.flatView && !selectedItem.IsParent {
+		return n.revealFlatItem(*selectedItem)
+	}
+
+	if selectedItem.IsParent {
+		if err := n.GoUp(); err != nil {
+			return err
+		}
+		n.checkExitPattern()
+		return nil
+	}
+
+	if selectedItem.IsDir || selectedItem.LinkDir {
+		// Bundles such as Foo.app open in their app, see EnterSelected
+		if opensAsBundle(*selectedItem, runtime.GOOS) && !n.config.Pick {
+			if n.execDisabled() {
+				return nil
+			}
+			return n.runCommand(defaultOpenCommand(selectedItem.Path))
+		}
+
+		// Navigate into directory, through a chain of single subdirectories if set
+		target := selectedItem.Path
+		if n.config.SkipSingleChild {
+			target = n.singleChildEnd(target)
+		}
+		if err := n.changeDirectory(target); err != nil {
+			return err
+		}
+		n.checkExitPattern()
+		return nil
 	} else {
-		// Open file's parent directory in terminal
-		return n.openInTerminal(selectedItem.Path, false)
+		// Run the configured action, by default opening the file's parent in a terminal
+		return n.openFile(*selectedItem)
+	}
+}
+
+// singleChildEnd follows dir down through directories whose only entry is a
+// subdirectory, as in Java package paths like src/main/java/com/example, and
+// returns the first one that holds anything else. Symlinks are not followed,
+// at most max_depth levels are descended, and the chain stops above a
+// directory that cannot be read.
+func (n *Navigator) singleChildEnd(dir string) string {
+	entries, err := n.source.ReadDir(dir)
+	for depth := 0; n.config.MaxDepth <= 0 || depth < n.config.MaxDepth; depth++ {
+		if err != nil || len(entries) != 1 || !entries[0].IsDir {
+			break
+		}
+		child := entries[0].Path
+		childEntries, childErr := n.source.ReadDir(child)
+		if childErr != nil {
+			break
+		}
+		dir, entries = child, childEntries
 	}
+	return dir
+}
+
+// EnterSelected descends into the selected directory, even a bundle that
+// OpenSelected would hand to the default app. On a flat view result it opens
+// the file itself, as Enter does in the file's own directory, instead of
+// revealing it there.
+func (n *Navigator) EnterSelected() error {
+	selectedItem := n.GetSelectedItem()
+	if n.flatView && selectedItem != nil && !selectedItem.IsParent && !n.config.Pick {
+		return n.openFlatItem(*selectedItem)
+	}
+	if selectedItem == nil || !selectedItem.IsDir || selectedItem.IsParent || n.flatView {
+		return n.OpenSelected()
+	}
+	if err := n.changeDirectory(selectedItem.Path); err != nil {
+		return err
+	}
+	n.checkExitPattern()
+	return nil
+}
+
+// checkExitPattern requests an exit with the current path when its name matches
+// the configured exit pattern.
+func (n *Navigator) checkExitPattern() {
+	if n.config.ExitPattern == "" {
+		return
+	}
+	if matched, _ := filepath.Match(n.config.ExitPattern, filepath.Base(n.currentPath)); matched {
+		n.exitPath = n.currentPath
+	}
+}
+
+// PickCurrentDir requests an exit with the current directory in --pick mode,
+// for choosing a directory rather than a file.
+func (n *Navigator) PickCurrentDir() {
+	if !n.config.Pick {
+		n.SetStatusMessage("Picking a directory needs --pick")
+		return
+	}
+	n.exitPath = n.currentPath
+}
+
+// GetExitPath returns the path to print on exit, or "" when no exit was requested.
+func (n *Navigator) GetExitPath() string {
+	return n.exitPath
+}
+
+// GoUp navigates to the parent of the current directory.
+func (n *Navigator) GoUp() error {
+	if isRoot(n.currentPath) {
+		return nil // Already at root
+	}
+	if n.currentPath == n.sessionRoot {
+		n.SetStatusMessage("At the session root (R clears it)")
+		return nil
+	}
+
+	// Select and mark the directory we came from
+	cameFrom := n.currentPath
+	if err := n.changeDirectory(filepath.Dir(n.currentPath)); err != nil {
+		return err
+	}
+	n.selectName(filepath.Base(cameFrom))
+	n.cameFrom = cameFrom
+	return nil
+}
+
+// GoHome navigates to the user's home directory, or the login directory when
+// browsing a remote source.
+func (n *Navigator) GoHome() error {
+	if remote, ok := n.source.(remoteSource); ok {
+		return n.jumpTo(remote.Home())
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("no home directory: %w", err)
+	}
+	return n.jumpTo(filepath.Clean(home))
+}
+
+// GoRoot navigates to the root of the filesystem, on Windows that of the
+// current directory's volume.
+func (n *Navigator) GoRoot() error {
+	return n.jumpTo(filepath.VolumeName(n.currentPath) + string(filepath.Separator))
+}
+
+// GoStart navigates back to the directory nav was started in.
+func (n *Navigator) GoStart() error {
+	return n.jumpTo(n.startPath)
+}
+
+// jumpTo navigates to the directory path, checking first that it can be
+// entered so a failed jump leaves the current directory as it was.
+func (n *Navigator) jumpTo(path string) error {
+	info, err := n.source.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return n.changeDirectory(path)
+}
+
+// SetSessionRoot enters the selected directory, or stays in the current one
+// when a file is selected, and stops going up above it for the rest of the
+// session.
+func (n *Navigator) SetSessionRoot() error {
+	root := n.currentPath
+	if selectedItem := n.GetSelectedItem(); selectedItem != nil && selectedItem.IsDir && !selectedItem.IsParent && !n.flatView {
+		root = selectedItem.Path
+	}
+	n.sessionRoot = root
+	if root == n.currentPath {
+		return n.Refresh()
+	}
+	return n.changeDirectory(root)
+}
+
+// ClearSessionRoot lets navigation go above the session root again.
+func (n *Navigator) ClearSessionRoot() error {
+	n.sessionRoot = ""
+	return n.Refresh()
+}
+
+// GetSessionRoot returns the directory going up stops at, or "" when unset.
+func (n *Navigator) GetSessionRoot() string {
+	return n.sessionRoot
+}
+
+// GetCameFrom returns the directory just left by going up, or "" after any
+// other navigation.
+func (n *Navigator) GetCameFrom() string {
+	return n.cameFrom
+}
+
+// GoToPath jumps to the path typed by the user, relative to the current directory
+// unless absolute. A trailing separator requires a directory; without one a file
+// is revealed by opening its parent with the file selected.
+func (n *Navigator) GoToPath(input string) error {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil
+	}
+	dirOnly := strings.HasSuffix(input, "/") || strings.HasSuffix(input, string(filepath.Separator))
+
+	target := normalizePath(input, n.currentPath)
+
+	info, err := n.source.Stat(target)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return n.changeDirectory(target)
+	}
+	if dirOnly {
+		return fmt.Errorf("%s is not a directory", input)
+	}
+
+	if err := n.changeDirectory(filepath.Dir(target)); err != nil {
+		return err
+	}
+	n.selectName(filepath.Base(target))
+	return nil
+}
+
+// normalizePath cleans a path given by the user: slashes become the platform's
+// separator, repeated separators and "." and ".." elements are dropped, and a
+// relative path is resolved against base. Roots keep their separator.
+func normalizePath(path, base string) string {
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return filepath.Clean(path)
+}
+
+// selectName moves the selection to the visible item called name, if present.
+func (n *Navigator) selectName(name string) bool {
+	for i, item := range n.filteredItems {
+		if item.Name == name {
+			n.selectedIdx = i
+			return true
+		}
+	}
+	return false
+}
+
+// changeDirectory makes path the current directory and rescans it.
+func (n *Navigator) changeDirectory(path string) error {
+	prevPath, prevSelected := n.currentPath, ""
+	if selectedItem := n.GetSelectedItem(); selectedItem != nil {
+		prevSelected = selectedItem.Name
+	}
+	n.saveViewState()
+
+	n.currentPath = path
+	n.cameFrom = ""
+	n.selectedIdx = 0
+	n.scrollOffset = 0
+	n.flatView = false
+	n.extFilter = ""
+	n.expanded = nil
+	n.searchTerm = ""
+	n.searchMode = false
+	n.marked = make(map[string]bool)
+	if err := n.ScanDirectory(); err != nil {
+		return err
+	}
+	n.restoreViewState()
+	n.history.visit(n.currentPath, n.config.HistorySize)
+	if prevPath != n.currentPath {
+		n.lastDir, n.lastSelected = prevPath, prevSelected
+	}
+	return nil
+}
+
+// NextSibling navigates to the next sibling directory of the current directory.
+func (n *Navigator) NextSibling() error {
+	return n.moveToSibling(1)
+}
+
+// PrevSibling navigates to the previous sibling directory of the current directory.
+func (n *Navigator) PrevSibling() error {
+	return n.moveToSibling(-1)
+}
+
+// moveToSibling navigates delta positions among the directories sharing the current parent.
+func (n *Navigator) moveToSibling(delta int) error {
+	if isRoot(n.currentPath) || n.currentPath == n.sessionRoot {
+		return nil // Root has no siblings, nor does the session root
+	}
+	parentPath := filepath.Dir(n.currentPath)
+
+	entries, err := n.source.ReadDir(parentPath)
+	if err != nil {
+		return err
+	}
+
+	// Sources return entries sorted by name, matching the listing order
+	var siblings []string
+	for _, entry := range entries {
+		if entry.IsDir {
+			siblings = append(siblings, entry.Name)
+		}
+	}
+
+	currentName := filepath.Base(n.currentPath)
+	currentIdx := -1
+	for i, name := range siblings {
+		if name == currentName {
+			currentIdx = i
+			break
+		}
+	}
+	if currentIdx == -1 {
+		return nil
+	}
+
+	targetIdx := currentIdx + delta
+	if targetIdx < 0 || targetIdx >= len(siblings) {
+		if !n.config.WrapSiblings {
+			return nil
+		}
+		targetIdx = (targetIdx%len(siblings) + len(siblings)) % len(siblings)
+	}
+	if targetIdx == currentIdx {
+		return nil
+	}
+
+	return n.changeDirectory(filepath.Join(parentPath, siblings[targetIdx]))
 }
 
 // OpenSelectedInTerminal opens the selected item in a new terminal.
 func (n *Navigator) OpenSelectedInTerminal() error {
-	selectedItem := n.GetSelectedItem()
+	selectedItem := n.requireSelection()
 	if selectedItem == nil {
 		return nil
 	}
@@ -187,6 +812,179 @@ func (n *Navigator) OpenSelectedInTerminal() error {
 	return n.openInTerminal(selectedItem.Path, selectedItem.IsDir)
 }
 
+// ToggleMark marks or unmarks the selected item. The parent entry cannot be marked.
+func (n *Navigator) ToggleMark() {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil || selectedItem.IsParent {
+		return
+	}
+	if n.marked[selectedItem.Path] {
+		delete(n.marked, selectedItem.Path)
+	} else {
+		n.marked[selectedItem.Path] = true
+	}
+}
+
+// MarkAll marks every visible item, leaving out those the search filters away
+// and the parent entry. It returns how many items were newly marked.
+func (n *Navigator) MarkAll() int {
+	count := 0
+	for _, item := range n.filteredItems {
+		if item.IsParent || n.marked[item.Path] {
+			continue
+		}
+		n.marked[item.Path] = true
+		count++
+	}
+	return count
+}
+
+// ClearMarks unmarks every item and returns how many were marked.
+func (n *Navigator) ClearMarks() int {
+	count := len(n.marked)
+	n.marked = make(map[string]bool)
+	return count
+}
+
+// IsMarked reports whether the item at path is marked.
+func (n *Navigator) IsMarked(path string) bool {
+	return n.marked[path]
+}
+
+// GetMarkedItems returns the marked items in listing order.
+func (n *Navigator) GetMarkedItems() []FileItem {
+	var markedItems []FileItem
+	for _, item := range n.items {
+		if n.marked[item.Path] {
+			markedItems = append(markedItems, item)
+		}
+	}
+	return markedItems
+}
+
+// HiddenCount returns how many of the listed items are hidden dotfiles.
+func (n *Navigator) HiddenCount() int {
+	count := 0
+	for _, item := range n.filteredItems {
+		if item.IsHidden {
+			count++
+		}
+	}
+	return count
+}
+
+// MarkMatching marks, or unmarks when mark is false, every visible item whose
+// name matches the glob pattern. Directories match without their trailing
+// slash. It returns the number of items whose mark changed.
+func (n *Navigator) MarkMatching(pattern string, mark bool) (int, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid pattern %q", pattern)
+	}
+
+	changed := 0
+	for _, item := range n.filteredItems {
+		if item.IsParent || n.marked[item.Path] == mark {
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, strings.TrimSuffix(item.Name, "/")); !matched {
+			continue
+		}
+		if mark {
+			n.marked[item.Path] = true
+		} else {
+			delete(n.marked, item.Path)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// StartPrompt opens a text input; submit is called with the text when it is confirmed.
+func (n *Navigator) StartPrompt(label, initial string, submit func(text string) error) {
+	n.prompt = &Prompt{Label: label, Text: initial, Cursor: len([]rune(initial)), submit: submit}
+}
+
+// GetPrompt returns the active prompt, or nil when none is open.
+func (n *Navigator) GetPrompt() *Prompt {
+	return n.prompt
+}
+
+// SetPromptText replaces the text of the active prompt and moves the cursor
+// to its end.
+func (n *Navigator) SetPromptText(text string) {
+	if n.prompt != nil {
+		n.prompt.Text = text
+		n.prompt.Cursor = len([]rune(text))
+	}
+}
+
+// SetPromptCursor moves the cursor of the active prompt to rune offset pos,
+// within the text.
+func (n *Navigator) SetPromptCursor(pos int) {
+	if n.prompt != nil {
+		n.prompt.Cursor = min(max(pos, 0), len([]rune(n.prompt.Text)))
+	}
+}
+
+// InsertPromptText types text at the cursor of the active prompt.
+func (n *Navigator) InsertPromptText(text string) {
+	if n.prompt == nil {
+		return
+	}
+	runes := []rune(n.prompt.Text)
+	cursor := n.prompt.Cursor
+	n.prompt.Text = string(runes[:cursor]) + text + string(runes[cursor:])
+	n.prompt.Cursor = cursor + len([]rune(text))
+}
+
+// DeletePromptBackward removes the rune before the cursor of the active prompt.
+func (n *Navigator) DeletePromptBackward() {
+	if n.prompt == nil || n.prompt.Cursor == 0 {
+		return
+	}
+	runes := []rune(n.prompt.Text)
+	cursor := n.prompt.Cursor
+	n.prompt.Text = string(runes[:cursor-1]) + string(runes[cursor:])
+	n.prompt.Cursor = cursor - 1
+}
+
+// CancelPrompt closes the active prompt without submitting it.
+func (n *Navigator) CancelPrompt() {
+	n.prompt = nil
+}
+
+// SubmitPrompt closes the active prompt and runs its submit function.
+func (n *Navigator) SubmitPrompt() error {
+	p := n.prompt
+	if p == nil {
+		return nil
+	}
+	n.prompt = nil
+	return p.submit(p.Text)
+}
+
+// OpenMarkedInTerminal opens a new terminal for each marked directory.
+func (n *Navigator) OpenMarkedInTerminal() error {
+	var errs []error
+	for _, item := range n.GetMarkedDirs() {
+		if err := n.openInTerminal(item.Path, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetMarkedDirs returns the marked directories in listing order.
+func (n *Navigator) GetMarkedDirs() []FileItem {
+	var dirs []FileItem
+	for _, item := range n.GetMarkedItems() {
+		if item.IsDir {
+			dirs = append(dirs, item)
+		}
+	}
+	return dirs
+}
+
 // ToggleSearchMode toggles search mode on/off.
 func (n *Navigator) ToggleSearchMode() {
 	n.searchMode = !n.searchMode
@@ -202,24 +1000,134 @@ func (n *Navigator) SetSearchTerm(term string) {
 	n.filterItems()
 }
 
-// filterItems filters items based on search term.
+// filterItems filters items based on search term and the extension filter.
 func (n *Navigator) filterItems() {
-	if n.searchTerm == "" {
+	if n.searchTerm == "" || n.config.SearchHighlight {
+		// Highlight mode keeps every entry visible and only moves the selection
 		n.filteredItems = n.items
 	} else {
 		n.filteredItems = []FileItem{}
 		lowerSearchTerm := strings.ToLower(n.searchTerm)
 		for _, item := range n.items {
-			if strings.Contains(strings.ToLower(item.Name), lowerSearchTerm) {
+			if strings.Contains(strings.ToLower(n.searchText(item)), lowerSearchTerm) {
 				n.filteredItems = append(n.filteredItems, item)
 			}
 		}
 	}
+	if n.extFilter != "" {
+		n.filteredItems = n.filterByExt(n.filteredItems)
+	}
 
 	// Reset selection if it's out of bounds
 	if n.selectedIdx >= len(n.filteredItems) {
 		n.selectedIdx = 0
 	}
+
+	if n.config.SearchHighlight {
+		n.stepMatch(0)
+	}
+}
+
+// IsSearchMatch reports whether item matches the active search term.
+func (n *Navigator) IsSearchMatch(item FileItem) bool {
+	if n.searchTerm == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(n.searchText(item)), strings.ToLower(n.searchTerm))
+}
+
+// ToggleSearchHighlight switches search between filtering the list and
+// highlighting matches in the full list.
+func (n *Navigator) ToggleSearchHighlight() {
+	n.config.SearchHighlight = !n.config.SearchHighlight
+	n.filterItems()
+}
+
+// NextMatch moves the selection to the next matching item, wrapping at the end.
+func (n *Navigator) NextMatch() {
+	n.stepMatch(1)
+}
+
+// PrevMatch moves the selection to the previous matching item, wrapping at the start.
+func (n *Navigator) PrevMatch() {
+	n.stepMatch(-1)
+}
+
+// stepMatch moves the selection to the next (delta 1) or previous (delta -1)
+// matching item, wrapping around the list. With delta 0 the selection stays on
+// the current item if it already matches. It reports whether a match was found.
+func (n *Navigator) stepMatch(delta int) bool {
+	if n.searchTerm == "" {
+		return false
+	}
+	return n.stepWhere(delta, true, n.IsSearchMatch)
+}
+
+// NextHidden moves the selection to the next hidden entry, wrapping at the
+// end when wrap_hidden is set. It reports whether there was one to go to.
+func (n *Navigator) NextHidden() bool {
+	return n.stepWhere(1, n.config.WrapHidden, isHiddenItem)
+}
+
+// PrevHidden moves the selection to the previous hidden entry, wrapping at
+// the start when wrap_hidden is set.
+func (n *Navigator) PrevHidden() bool {
+	return n.stepWhere(-1, n.config.WrapHidden, isHiddenItem)
+}
+
+func isHiddenItem(item FileItem) bool {
+	return item.IsHidden
+}
+
+// stepWhere moves the selection delta steps at a time to the nearest listed
+// item satisfying match, as stepMatch describes, stopping at the ends of the
+// list unless wrap is set.
+func (n *Navigator) stepWhere(delta int, wrap bool, match func(FileItem) bool) bool {
+	total := len(n.filteredItems)
+	if total == 0 {
+		return false
+	}
+
+	step, first := delta, 1
+	if delta == 0 {
+		step, first = 1, 0
+	}
+	for i := first; i < total+first; i++ {
+		idx := n.selectedIdx + i*step
+		if !wrap && (idx < 0 || idx >= total) {
+			return false
+		}
+		idx = (idx%total + total) % total
+		if match(n.filteredItems[idx]) {
+			n.selectedIdx = idx
+			return true
+		}
+	}
+	return false
+}
+
+// searchText returns the text of item that search terms are matched against.
+func (n *Navigator) searchText(item FileItem) string {
+	if n.config.SearchPaths {
+		return n.relativePath(item)
+	}
+	return item.Name
+}
+
+// relativePath returns item's path relative to the current directory, using
+// forward slashes so queries like "src/ma" match on every platform.
+func (n *Navigator) relativePath(item FileItem) string {
+	rel, err := filepath.Rel(n.currentPath, item.Path)
+	if err != nil {
+		return item.Name
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ToggleSearchPaths toggles matching searches against relative paths instead of names.
+func (n *Navigator) ToggleSearchPaths() {
+	n.config.SearchPaths = !n.config.SearchPaths
+	n.filterItems()
 }
 
 // detectTerminalCommand detects the appropriate terminal command to use.
@@ -263,8 +1171,12 @@ func detectTerminalCommand() (string, []string) {
 	}
 }
 
-// openInTerminal opens a new terminal window at the given path.
-func (n *Navigator) openInTerminal(path string, isDir bool) error {
+// openInTerminal opens a new terminal window at the given path. When program is
+// given, the terminal runs it instead of a shell.
+func (n *Navigator) openInTerminal(path string, isDir bool, program ...string) error {
+	if n.execDisabled() {
+		return nil
+	}
 	workingDir := path
 	if !isDir {
 		workingDir = filepath.Dir(path)
@@ -307,18 +1219,70 @@ func (n *Navigator) openInTerminal(path string, isDir bool) error {
 		cmd = exec.Command(command, allArgs...)
 	}
 
+	if len(program) > 0 {
+		if err := appendProgram(cmd, command, program); err != nil {
+			return err
+		}
+	}
+
 	// Start the command in the background
+	return n.runCommand(cmd)
+}
+
+// appendProgram adds program to a terminal invocation using the flag the
+// terminal expects for running a command.
+func appendProgram(cmd *exec.Cmd, command string, program []string) error {
+	switch filepath.Base(command) {
+	case "open":
+		return fmt.Errorf("open cannot run a program in a new terminal; set $TERMINAL")
+	case "cmd":
+		// Replace the trailing "cd <dir>" so the new window runs program instead
+		cmd.Args = append(cmd.Args[:len(cmd.Args)-2], program...)
+	case "gnome-terminal", "wezterm", "kitty":
+		cmd.Args = append(append(cmd.Args, "--"), program...)
+	default:
+		cmd.Args = append(append(cmd.Args, "-e"), program...)
+	}
+	return nil
+}
+
+// OpenNewInstance starts another nav, in a new terminal, rooted at the selected
+// directory (or the selected file's directory).
+func (n *Navigator) OpenNewInstance() error {
+	selectedItem := n.requireSelection()
+	if selectedItem == nil {
+		return nil
+	}
+	dir := selectedItem.Path
+	if !selectedItem.IsDir {
+		dir = filepath.Dir(dir)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate nav: %v", err)
+	}
+	return n.openInTerminal(dir, true, executable, dir)
+}
+
+// startCommand starts cmd without waiting for it to finish.
+func startCommand(cmd *exec.Cmd) error {
 	return cmd.Start()
 }
 
-// isRootPath checks if the given path is a root path that might cause issues
-func (n *Navigator) isRootPath(path string) bool {
-	// Check for common root paths that might not be accessible
-	rootPaths := []string{"/", "C:\\", "D:\\", "E:\\", "F:\\"}
-	for _, root := range rootPaths {
-		if path == root {
-			return true
-		}
+// fallbackDir returns the home directory, or the working directory when no
+// home is set ($HOME or %USERPROFILE% unset, as in minimal containers).
+func fallbackDir() (string, error) {
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return homeDir, nil
 	}
-	return false
+	return os.Getwd()
+}
+
+// isRoot reports whether path is the top of a filesystem: / on Unix, and on
+// Windows any drive root such as D:\ or a UNC share such as \\server\share\.
+// Dir returns such a path unchanged, so there is no parent to go up to.
+func isRoot(path string) bool {
+	path = filepath.Clean(path)
+	return filepath.Dir(path) == path
 }
\ No newline at end of file
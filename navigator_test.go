@@ -2,7 +2,11 @@ package main
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -79,12 +83,22 @@ func TestScanDirectory(t *testing.T) {
 	assertContains(t, scannedNames, "file1.txt")
 	assertContains(t, scannedNames, ".hidden_file")
 
+	// Only the ../ entry is flagged as the parent
+	for i, item := range items {
+		if item.IsParent != (item.Name == "../") {
+			t.Errorf("Expected IsParent only on ../, got %v for %q", item.IsParent, item.Name)
+		}
+		if item.IsParent && i != 0 {
+			t.Errorf("Expected the parent entry first, got it at %d", i)
+		}
+	}
+
 	// Verify item counts
 	dirCount := 0
 	fileCount := 0
 	hiddenFileCount := 0
 	for _, item := range items {
-		if item.IsDir && item.Name != "../" {
+		if item.IsDir && !item.IsParent {
 			dirCount++
 		}
 		if !item.IsDir && !item.IsHidden {
@@ -140,6 +154,193 @@ func TestMoveSelection(t *testing.T) {
 	}
 }
 
+func TestNextPrevHidden(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{".git", "src"} {
+		os.Mkdir(filepath.Join(tempDir, name), 0755)
+	}
+	for _, name := range []string{".bashrc", "notes.txt", ".profile", "todo.txt"} {
+		os.WriteFile(filepath.Join(tempDir, name), nil, 0644)
+	}
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	var hidden []string
+	for _, item := range nav.GetItems() {
+		if item.IsHidden {
+			hidden = append(hidden, item.Name)
+		}
+	}
+	if len(hidden) != 3 {
+		t.Fatalf("Expected 3 hidden entries, got %v", hidden)
+	}
+
+	// Forward through the hidden entries only, stopping at the last
+	for _, want := range hidden {
+		if !nav.NextHidden() || nav.GetSelectedItem().Name != want {
+			t.Fatalf("Expected NextHidden to select %s, got %s", want, nav.GetSelectedItem().Name)
+		}
+	}
+	if nav.NextHidden() || nav.GetSelectedItem().Name != hidden[2] {
+		t.Errorf("Expected NextHidden to stop at %s, got %s", hidden[2], nav.GetSelectedItem().Name)
+	}
+	if !nav.PrevHidden() || nav.GetSelectedItem().Name != hidden[1] {
+		t.Errorf("Expected PrevHidden to select %s, got %s", hidden[1], nav.GetSelectedItem().Name)
+	}
+
+	// With wrap_hidden it goes around
+	cfg := nav.GetConfig()
+	cfg.WrapHidden = true
+	nav.SetConfig(cfg)
+	selectByName(t, nav, hidden[2])
+	if !nav.NextHidden() || nav.GetSelectedItem().Name != hidden[0] {
+		t.Errorf("Expected NextHidden to wrap to %s, got %s", hidden[0], nav.GetSelectedItem().Name)
+	}
+	if !nav.PrevHidden() || nav.GetSelectedItem().Name != hidden[2] {
+		t.Errorf("Expected PrevHidden to wrap to %s, got %s", hidden[2], nav.GetSelectedItem().Name)
+	}
+
+	// Nothing to find without hidden entries
+	selectByName(t, nav, "src")
+	nav.SetSearchTerm("txt")
+	if nav.NextHidden() {
+		t.Error("Expected no hidden entries among the search results")
+	}
+}
+
+func TestSkipSingleChild(t *testing.T) {
+	tempDir := t.TempDir()
+	java := filepath.Join(tempDir, "src", "main", "java")
+	os.MkdirAll(filepath.Join(java, "com", "example", "app"), 0755)
+	os.Mkdir(filepath.Join(java, "com", "example", "util"), 0755)
+	os.MkdirAll(filepath.Join(tempDir, "docs", "api"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "docs", "index.md"), nil, 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// Off by default: Enter goes one level
+	selectByName(t, nav, "src")
+	nav.OpenSelected()
+	if want := filepath.Join(tempDir, "src"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected %q, got %q", want, nav.GetCurrentPath())
+	}
+
+	cfg := nav.GetConfig()
+	cfg.SkipSingleChild = true
+	nav.SetConfig(cfg)
+	nav.GoToPath(tempDir)
+
+	// The chain collapses down to example, the first directory that branches
+	selectByName(t, nav, "src")
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if want := filepath.Join(java, "com", "example"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected the chain to end at %q, got %q", want, nav.GetCurrentPath())
+	}
+
+	// A directory with a file beside its subdirectory stops right away
+	nav.GoToPath(tempDir)
+	selectByName(t, nav, "docs")
+	nav.OpenSelected()
+	if want := filepath.Join(tempDir, "docs"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected to stop at %q, got %q", want, nav.GetCurrentPath())
+	}
+
+	// An empty leaf ends the chain at the leaf
+	nav.GoToPath(filepath.Join(java, "com", "example"))
+	selectByName(t, nav, "app")
+	nav.OpenSelected()
+	if want := filepath.Join(java, "com", "example", "app"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected %q, got %q", want, nav.GetCurrentPath())
+	}
+
+	// An unreadable leaf ends the chain above it
+	locked := filepath.Join(tempDir, "lib", "only", "locked")
+	os.MkdirAll(locked, 0755)
+	nav.SetSource(lockedSource{osSource{}, locked})
+	nav.GoToPath(tempDir)
+	selectByName(t, nav, "lib")
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if want := filepath.Dir(locked); nav.GetCurrentPath() != want {
+		t.Errorf("Expected the chain to stop at %q, got %q", want, nav.GetCurrentPath())
+	}
+}
+
+// lockedSource fails to list one directory, as if it had no read permission.
+type lockedSource struct {
+	FileSource
+	locked string
+}
+
+func (s lockedSource) ReadDir(path string) ([]FileItem, error) {
+	if path == s.locked {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrPermission}
+	}
+	return s.FileSource.ReadDir(path)
+}
+
+func TestStepSelectionAtEdges(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Mkdir(filepath.Join(tempDir, "a"), 0755)
+	os.Mkdir(filepath.Join(tempDir, "b"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	last := len(nav.GetItems()) - 1
+
+	// By default the selection stops at both ends
+	nav.StepSelection(-1)
+	if nav.GetSelectedIndex() != 0 || nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected Up at the top to stay at 0 in %q, got %d in %q", tempDir, nav.GetSelectedIndex(), nav.GetCurrentPath())
+	}
+	nav.MoveSelection(last)
+	nav.StepSelection(1)
+	if nav.GetSelectedIndex() != last || nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected Down at the bottom to stay at %d, got %d in %q", last, nav.GetSelectedIndex(), nav.GetCurrentPath())
+	}
+
+	cfg := nav.GetConfig()
+	cfg.TopGoesUp, cfg.BottomEnters = true, true
+	nav.SetConfig(cfg)
+
+	// Down on the last entry, the directory b, enters it
+	if err := nav.StepSelection(1); err != nil {
+		t.Fatalf("StepSelection(1) failed: %v", err)
+	}
+	if want := filepath.Join(tempDir, "b"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected Down at the bottom to enter %q, got %q", want, nav.GetCurrentPath())
+	}
+
+	// Up on the first entry goes back to the parent
+	nav.MoveSelection(-10)
+	if err := nav.StepSelection(-1); err != nil {
+		t.Fatalf("StepSelection(-1) failed: %v", err)
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected Up at the top to go to %q, got %q", tempDir, nav.GetCurrentPath())
+	}
+
+	// Away from the edges the selection just moves
+	nav.MoveSelection(-10)
+	nav.StepSelection(1)
+	if nav.GetSelectedIndex() != 1 || nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected Down to select entry 1 in %q, got %d in %q", tempDir, nav.GetSelectedIndex(), nav.GetCurrentPath())
+	}
+
+	// Down on a file at the bottom stays put
+	os.WriteFile(filepath.Join(tempDir, "z.txt"), nil, 0644)
+	nav.Refresh()
+	nav.MoveSelection(10)
+	nav.StepSelection(1)
+	if selectedItem := nav.GetSelectedItem(); nav.GetCurrentPath() != tempDir || selectedItem == nil || selectedItem.Name != "z.txt" {
+		t.Errorf("Expected Down on the last file to stay on it in %q, got %q", tempDir, nav.GetCurrentPath())
+	}
+}
+
 func TestSearchFunctionality(t *testing.T) {
 	tempDir, cleanup := createTestDir(t)
 	defer cleanup()
@@ -207,7 +408,827 @@ func TestGetSelectedItem(t *testing.T) {
 	}
 }
 
+func TestSiblingNavigation(t *testing.T) {
+	parentDir := t.TempDir()
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		os.Mkdir(filepath.Join(parentDir, name), 0755)
+	}
+	os.WriteFile(filepath.Join(parentDir, "file.txt"), []byte("content"), 0644)
+
+	nav, _ := NewNavigator(filepath.Join(parentDir, "beta"))
+	nav.ScanDirectory()
+	nav.MoveSelection(1)
+
+	// Next sibling skips files and resets the selection
+	if err := nav.NextSibling(); err != nil {
+		t.Fatalf("NextSibling failed: %v", err)
+	}
+	if filepath.Base(nav.GetCurrentPath()) != "gamma" {
+		t.Errorf("NextSibling expected gamma, got %s", nav.GetCurrentPath())
+	}
+	if nav.GetSelectedIndex() != 0 {
+		t.Errorf("NextSibling did not reset selection, got %d", nav.GetSelectedIndex())
+	}
+
+	// Without wrapping, the last sibling stays put
+	nav.NextSibling()
+	if filepath.Base(nav.GetCurrentPath()) != "gamma" {
+		t.Errorf("NextSibling without wrap expected to stay at gamma, got %s", nav.GetCurrentPath())
+	}
+
+	// With wrapping, moving past the end goes to the first sibling
+	cfg := nav.GetConfig()
+	cfg.WrapSiblings = true
+	nav.SetConfig(cfg)
+	nav.NextSibling()
+	if filepath.Base(nav.GetCurrentPath()) != "alpha" {
+		t.Errorf("NextSibling with wrap expected alpha, got %s", nav.GetCurrentPath())
+	}
+
+	nav.PrevSibling()
+	if filepath.Base(nav.GetCurrentPath()) != "gamma" {
+		t.Errorf("PrevSibling with wrap expected gamma, got %s", nav.GetCurrentPath())
+	}
+
+	nav.PrevSibling()
+	if filepath.Base(nav.GetCurrentPath()) != "beta" {
+		t.Errorf("PrevSibling expected beta, got %s", nav.GetCurrentPath())
+	}
+}
+
+func TestToggleMark(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// The parent entry cannot be marked
+	nav.ToggleMark()
+	if len(nav.GetMarkedItems()) != 0 {
+		t.Error("ToggleMark should not mark the parent entry")
+	}
+
+	selectByName(t, nav, "file1.txt")
+	nav.ToggleMark()
+	if !nav.IsMarked(filepath.Join(tempDir, "file1.txt")) {
+		t.Error("ToggleMark did not mark file1.txt")
+	}
+
+	nav.ToggleMark()
+	if len(nav.GetMarkedItems()) != 0 {
+		t.Error("ToggleMark did not unmark file1.txt")
+	}
+}
+
+func TestMarkMatching(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.tmp", "b.tmp", "keep.txt", "notes.tmp.bak"} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("x"), 0644)
+	}
+	os.Mkdir(filepath.Join(tempDir, "cache.tmp"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	changed, err := nav.MarkMatching("*.tmp", true)
+	if err != nil {
+		t.Fatalf("MarkMatching failed: %v", err)
+	}
+	if changed != 3 {
+		t.Errorf("Expected 3 items marked, got %d", changed)
+	}
+	for _, name := range []string{"a.tmp", "b.tmp", "cache.tmp"} {
+		if !nav.IsMarked(filepath.Join(tempDir, name)) {
+			t.Errorf("Expected %s to be marked", name)
+		}
+	}
+
+	// Marking again changes nothing
+	if changed, _ := nav.MarkMatching("*.tmp", true); changed != 0 {
+		t.Errorf("Expected no new marks, got %d", changed)
+	}
+
+	changed, _ = nav.MarkMatching("b*", false)
+	if changed != 1 || nav.IsMarked(filepath.Join(tempDir, "b.tmp")) {
+		t.Errorf("Expected b.tmp unmarked, changed %d", changed)
+	}
+	if len(nav.GetMarkedItems()) != 2 {
+		t.Errorf("Expected 2 marked items left, got %d", len(nav.GetMarkedItems()))
+	}
+
+	// Only visible items are affected
+	nav.ToggleSearchMode()
+	nav.SetSearchTerm("keep")
+	nav.MarkMatching("*", true)
+	if len(nav.GetMarkedItems()) != 3 {
+		t.Errorf("Expected only keep.txt to be added, got %d marked", len(nav.GetMarkedItems()))
+	}
+
+	if _, err := nav.MarkMatching("[", true); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestMarkAllAndClearMarks(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// Everything but ../ is marked
+	if count := nav.MarkAll(); count != 4 {
+		t.Errorf("Expected 4 items marked, got %d", count)
+	}
+	if got := itemNames(nav.GetMarkedItems()); !reflect.DeepEqual(got, []string{"dir1", "dir2", ".hidden_file", "file1.txt"}) {
+		t.Errorf("Expected every entry marked, got %v", got)
+	}
+	if count := nav.ClearMarks(); count != 4 || len(nav.GetMarkedItems()) != 0 {
+		t.Errorf("Expected 4 marks cleared, got %d with %d left", count, len(nav.GetMarkedItems()))
+	}
+
+	// While filtering only the matches are marked
+	nav.ToggleSearchMode()
+	nav.SetSearchTerm("dir")
+	if count := nav.MarkAll(); count != 2 {
+		t.Errorf("Expected 2 matches marked, got %d", count)
+	}
+	if got := itemNames(nav.GetMarkedItems()); !reflect.DeepEqual(got, []string{"dir1", "dir2"}) {
+		t.Errorf("Expected only the matches marked, got %v", got)
+	}
+	nav.ClearMarks()
+	if len(nav.GetMarkedItems()) != 0 {
+		t.Error("Expected no marks after ClearMarks")
+	}
+}
+
+func TestRealPathThroughSymlink(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(tempDir, "target")
+	os.Mkdir(target, 0755)
+	link := filepath.Join(tempDir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	nav, _ := NewNavigator(target)
+	nav.ScanDirectory()
+	if nav.GetRealPath() != "" {
+		t.Errorf("Expected no real path for a directory reached directly, got %q", nav.GetRealPath())
+	}
+
+	nav.changeDirectory(link)
+	if nav.GetCurrentPath() != link {
+		t.Errorf("Expected logical path %q, got %q", link, nav.GetCurrentPath())
+	}
+	if nav.GetRealPath() != target {
+		t.Errorf("Expected resolved path %q, got %q", target, nav.GetRealPath())
+	}
+}
+
+func TestFollowSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	target := filepath.Join(tempDir, "target")
+	os.Mkdir(target, 0755)
+	link := filepath.Join(tempDir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "link")
+
+	nav.ToggleFollowSymlinks()
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected to stay in %q with following off, got %q", tempDir, nav.GetCurrentPath())
+	}
+	if !strings.Contains(nav.GetStatusMessage(), "Not following") {
+		t.Errorf("Expected a refusal message, got %q", nav.GetStatusMessage())
+	}
+
+	nav.ToggleFollowSymlinks()
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if nav.GetCurrentPath() != link {
+		t.Errorf("Expected to enter %q with following on, got %q", link, nav.GetCurrentPath())
+	}
+}
+
+func TestFallbackDirWithoutHome(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	t.Setenv("home", "")
+
+	wd, _ := os.Getwd()
+	dir, err := fallbackDir()
+	if err != nil {
+		t.Fatalf("fallbackDir failed without a home: %v", err)
+	}
+	if dir != wd {
+		t.Errorf("Expected fallback to the working directory %q, got %q", wd, dir)
+	}
+}
+
+func TestSelectFirstEntry(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	// Off by default: the selection starts on ../
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	if selected := nav.GetSelectedItem(); selected == nil || !selected.IsParent {
+		t.Errorf("Expected ../ selected by default, got %v", selected)
+	}
+
+	cfg := nav.GetConfig()
+	cfg.SelectFirstEntry = true
+	nav.SetConfig(cfg)
+	nav.changeDirectory(tempDir)
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "dir1" {
+		t.Errorf("Expected dir1 selected past ../, got %v", selected)
+	}
+
+	// An empty directory has nothing past ../
+	nav.changeDirectory(filepath.Join(tempDir, "dir1"))
+	if nav.GetSelectedIndex() != 0 {
+		t.Errorf("Expected ../ selected in an empty directory, got index %d", nav.GetSelectedIndex())
+	}
+
+	// Restoring a selection takes precedence
+	nav.changeDirectory(tempDir)
+	nav.MoveSelection(-1)
+	nav.Refresh()
+	if selected := nav.GetSelectedItem(); selected == nil || !selected.IsParent {
+		t.Errorf("Expected Refresh to keep ../ selected, got %v", selected)
+	}
+	nav.GoToPath("file1.txt")
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "file1.txt" {
+		t.Errorf("Expected GoToPath to select file1.txt, got %v", selected)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	// ../, dir1, dir2, .hidden_file, file1.txt
+	if got := nav.Position(); got != "1/5" {
+		t.Errorf("Expected 1/5 at the top, got %s", got)
+	}
+	nav.MoveSelection(2)
+	if got := nav.Position(); got != "3/5" {
+		t.Errorf("Expected 3/5 after moving down, got %s", got)
+	}
+
+	// Filtering changes the total
+	nav.ToggleSearchMode()
+	nav.SetSearchTerm("dir")
+	if got := nav.Position(); !strings.HasSuffix(got, "/2") {
+		t.Errorf("Expected a total of 2 while filtering, got %s", got)
+	}
+	nav.SetSearchTerm("nothing matches")
+	if got := nav.Position(); got != "0/0" {
+		t.Errorf("Expected 0/0 for an empty list, got %s", got)
+	}
+}
+
+func TestPick(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	os.WriteFile(filepath.Join(tempDir, "dir1", "inner.txt"), nil, 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.runCommand = func(cmd *exec.Cmd) error {
+		t.Errorf("Expected nothing to be started while picking, got %v", cmd.Args)
+		return nil
+	}
+	nav.ScanDirectory()
+
+	// Without --pick . does not exit
+	nav.PickCurrentDir()
+	if nav.GetExitPath() != "" {
+		t.Errorf("Expected no pick without --pick, got %q", nav.GetExitPath())
+	}
+
+	cfg := nav.GetConfig()
+	cfg.Pick = true
+	nav.SetConfig(cfg)
+
+	// Directories drill down, files are picked
+	selectByName(t, nav, "dir1")
+	nav.OpenSelected()
+	if nav.GetExitPath() != "" || nav.GetCurrentPath() != filepath.Join(tempDir, "dir1") {
+		t.Fatalf("Expected Enter to descend into dir1, at %s with exit %q", nav.GetCurrentPath(), nav.GetExitPath())
+	}
+	selectByName(t, nav, "inner.txt")
+	nav.OpenSelected()
+	if want := filepath.Join(tempDir, "dir1", "inner.txt"); nav.GetExitPath() != want {
+		t.Errorf("Expected %s picked, got %q", want, nav.GetExitPath())
+	}
+
+	nav, _ = NewNavigator(filepath.Join(tempDir, "dir2"))
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	nav.PickCurrentDir()
+	if want := filepath.Join(tempDir, "dir2"); nav.GetExitPath() != want {
+		t.Errorf("Expected the current directory %s picked, got %q", want, nav.GetExitPath())
+	}
+}
+
+func TestHiddenCount(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	os.Mkdir(filepath.Join(tempDir, ".config"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	if got := nav.HiddenCount(); got != 2 {
+		t.Errorf("Expected 2 hidden items, got %d", got)
+	}
+	if bar := buildStatusBar(nav, len(nav.GetItems())); !strings.Contains(bar, ", 2 hidden shown]") {
+		t.Errorf("Expected the status bar to count hidden items, got %q", bar)
+	}
+
+	// The segment disappears without hidden items
+	nav.changeDirectory(filepath.Join(tempDir, "dir1"))
+	if bar := buildStatusBar(nav, len(nav.GetItems())); strings.Contains(bar, "hidden") {
+		t.Errorf("Expected no hidden count in an empty directory, got %q", bar)
+	}
+}
+
+func TestParentLabel(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	// A label sorting after every name still comes first
+	nav, _ := NewNavigator(filepath.Join(tempDir, "dir1"))
+	cfg := nav.GetConfig()
+	cfg.ParentLabel = "~ up to {name}"
+	nav.SetConfig(cfg)
+	nav.changeDirectory(tempDir)
+
+	want := "~ up to " + filepath.Base(filepath.Dir(tempDir))
+	items := nav.GetItems()
+	if len(items) == 0 || items[0].Name != want || !items[0].IsParent {
+		t.Fatalf("Expected the parent first labeled %q, got %v", want, itemNames(items))
+	}
+
+	// It cannot be marked and still leads up
+	nav.ToggleMark()
+	if nav.IsMarked(items[0].Path) {
+		t.Error("Expected the parent entry not to be markable")
+	}
+	nav.OpenSelected()
+	if nav.GetCurrentPath() != filepath.Dir(tempDir) {
+		t.Errorf("Expected the parent entry to go up to %q, got %q", filepath.Dir(tempDir), nav.GetCurrentPath())
+	}
+}
+
+func TestCameFrom(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(filepath.Join(tempDir, "dir2"))
+	nav.ScanDirectory()
+	if err := nav.GoUp(); err != nil {
+		t.Fatalf("GoUp failed: %v", err)
+	}
+	want := filepath.Join(tempDir, "dir2")
+	if nav.GetCameFrom() != want {
+		t.Errorf("Expected came-from %q, got %q", want, nav.GetCameFrom())
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Path != nav.GetCameFrom() {
+		t.Errorf("Expected the came-from directory selected, got %v", selected)
+	}
+
+	// Going up through ../ records it too
+	nav.changeDirectory(filepath.Join(tempDir, "dir1"))
+	selectByName(t, nav, "../")
+	nav.OpenSelected()
+	if want := filepath.Join(tempDir, "dir1"); nav.GetCameFrom() != want {
+		t.Errorf("Expected came-from %q after ../, got %q", want, nav.GetCameFrom())
+	}
+
+	// Any other navigation clears it
+	selectByName(t, nav, "dir2")
+	nav.OpenSelected()
+	if nav.GetCameFrom() != "" {
+		t.Errorf("Expected came-from cleared, got %q", nav.GetCameFrom())
+	}
+}
+
+func TestExitPattern(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Mkdir(filepath.Join(tempDir, "other"), 0755)
+	os.Mkdir(filepath.Join(tempDir, "wt-feature"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// Off by default
+	selectByName(t, nav, "wt-feature")
+	nav.OpenSelected()
+	if nav.GetExitPath() != "" {
+		t.Errorf("Exit requested without an exit pattern: %q", nav.GetExitPath())
+	}
+
+	cfg := nav.GetConfig()
+	cfg.ExitPattern = "wt-*"
+	nav.SetConfig(cfg)
+	nav.changeDirectory(tempDir)
+
+	selectByName(t, nav, "other")
+	nav.OpenSelected()
+	if nav.GetExitPath() != "" {
+		t.Errorf("Exit requested for non-matching directory: %q", nav.GetExitPath())
+	}
+
+	nav.changeDirectory(tempDir)
+	selectByName(t, nav, "wt-feature")
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if want := filepath.Join(tempDir, "wt-feature"); nav.GetExitPath() != want {
+		t.Errorf("Expected exit path %q, got %q", want, nav.GetExitPath())
+	}
+}
+
+func TestOpenMarkedInTerminal(t *testing.T) {
+	t.Setenv("TERMINAL", "fake-terminal")
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	var started []*exec.Cmd
+	nav.runCommand = func(cmd *exec.Cmd) error {
+		started = append(started, cmd)
+		return nil
+	}
+
+	// Marked files are skipped; only directories get a terminal
+	for _, name := range []string{"dir1", "dir2", "file1.txt"} {
+		selectByName(t, nav, name)
+		nav.ToggleMark()
+	}
+
+	if err := nav.OpenMarkedInTerminal(); err != nil {
+		t.Fatalf("OpenMarkedInTerminal failed: %v", err)
+	}
+	if len(started) != 2 {
+		t.Fatalf("Expected 2 terminal invocations, got %d", len(started))
+	}
+	for i, name := range []string{"dir1", "dir2"} {
+		args := started[i].Args
+		if filepath.Base(started[i].Path) != "fake-terminal" {
+			t.Errorf("Invocation %d expected fake-terminal, got %s", i, started[i].Path)
+		}
+		if want := filepath.Join(tempDir, name); args[len(args)-1] != want {
+			t.Errorf("Invocation %d expected working directory %q, got args %v", i, want, args)
+		}
+	}
+}
+
+func TestOpenNewInstance(t *testing.T) {
+	t.Setenv("TERMINAL", "fake-terminal")
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	var started []*exec.Cmd
+	nav.runCommand = func(cmd *exec.Cmd) error {
+		started = append(started, cmd)
+		return nil
+	}
+	executable, _ := os.Executable()
+
+	// A directory roots the child there; a file roots it in its directory
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"dir1", filepath.Join(tempDir, "dir1")},
+		{"file1.txt", tempDir},
+	}
+	for _, tt := range tests {
+		started = nil
+		selectByName(t, nav, tt.name)
+		if err := nav.OpenNewInstance(); err != nil {
+			t.Fatalf("OpenNewInstance failed: %v", err)
+		}
+		if len(started) != 1 {
+			t.Fatalf("Expected 1 invocation, got %d", len(started))
+		}
+		args := started[0].Args
+		if len(args) < 2 || args[len(args)-2] != executable || args[len(args)-1] != tt.want {
+			t.Errorf("Selecting %s expected child %q %q, got args %v", tt.name, executable, tt.want, args)
+		}
+	}
+}
+
+func TestAppendProgram(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"gnome-terminal", "--working-directory", "/d"}, []string{"gnome-terminal", "--working-directory", "/d", "--", "nav", "/d"}},
+		{[]string{"xterm", "/d"}, []string{"xterm", "/d", "-e", "nav", "/d"}},
+		{[]string{"cmd", "/c", "start", "cmd", "/k", "cd", `C:\d`}, []string{"cmd", "/c", "start", "cmd", "/k", "nav", "/d"}},
+	}
+	for _, tt := range tests {
+		cmd := exec.Command(tt.args[0], tt.args[1:]...)
+		if err := appendProgram(cmd, tt.args[0], []string{"nav", "/d"}); err != nil {
+			t.Fatalf("appendProgram(%v) failed: %v", tt.args, err)
+		}
+		if strings.Join(cmd.Args, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("appendProgram(%v) expected %v, got %v", tt.args, tt.want, cmd.Args)
+		}
+	}
+
+	if err := appendProgram(exec.Command("open", "-a", "Terminal", "/d"), "open", []string{"nav"}); err == nil {
+		t.Error("Expected an error for macOS open")
+	}
+}
+
+func TestSearchPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	nav, _ := NewNavigator(tempDir)
+	nav.items = []FileItem{
+		{Name: "main.go", Path: filepath.Join(tempDir, "src", "main.go")},
+		{Name: "main_test.go", Path: filepath.Join(tempDir, "test", "main_test.go")},
+		{Name: "readme.md", Path: filepath.Join(tempDir, "readme.md")},
+	}
+	nav.ToggleSearchMode()
+
+	// Name matching ignores folder segments
+	nav.SetSearchTerm("src/ma")
+	if len(nav.GetItems()) != 0 {
+		t.Errorf("Name search for 'src/ma' expected no matches, got %v", nav.GetItems())
+	}
+
+	nav.ToggleSearchPaths()
+	filteredItems := nav.GetItems()
+	if len(filteredItems) != 1 || filteredItems[0].Name != "main.go" {
+		t.Errorf("Path search for 'src/ma' expected only main.go, got %v", filteredItems)
+	}
+
+	nav.SetSearchTerm("MAIN")
+	assertContainsAll(t, nav.GetItems(), []string{"main.go", "main_test.go"})
+
+	nav.SetSearchTerm("test/")
+	filteredItems = nav.GetItems()
+	if len(filteredItems) != 1 || filteredItems[0].Name != "main_test.go" {
+		t.Errorf("Path search for 'test/' expected only main_test.go, got %v", filteredItems)
+	}
+
+	if rel := nav.relativePath(nav.items[0]); rel != "src/main.go" {
+		t.Errorf("relativePath expected src/main.go, got %q", rel)
+	}
+}
+
+func TestHideParent(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	os.WriteFile(filepath.Join(tempDir, "dir1", "inner.txt"), []byte("content"), 0644)
+
+	nav, _ := NewNavigator(filepath.Join(tempDir, "dir1"))
+	cfg := nav.GetConfig()
+	cfg.HideParent = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	items := nav.GetItems()
+	for _, item := range items {
+		if item.IsParent {
+			t.Error("GetItems contains ../ with HideParent set")
+		}
+	}
+	if len(items) != 1 || items[0].Name != "inner.txt" {
+		t.Errorf("Expected only inner.txt, got %v", items)
+	}
+
+	// Going up still works through the method
+	if err := nav.GoUp(); err != nil {
+		t.Fatalf("GoUp failed: %v", err)
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("GoUp expected %s, got %s", tempDir, nav.GetCurrentPath())
+	}
+	if nav.GetSelectedIndex() != 0 {
+		t.Errorf("GoUp expected selection reset, got %d", nav.GetSelectedIndex())
+	}
+}
+
+func TestHighlightSearchStepping(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	names := []string{"alpha.go", "beta.md", "gamma.go", "delta.txt", "omega.go"}
+	for _, name := range names {
+		nav.items = append(nav.items, FileItem{Name: name, Path: filepath.Join(nav.GetCurrentPath(), name)})
+	}
+	nav.filterItems()
+
+	nav.ToggleSearchMode()
+	nav.ToggleSearchHighlight()
+	nav.MoveSelection(1) // beta.md
+	nav.SetSearchTerm(".go")
+
+	// Nothing is filtered out, and typing jumps to the first match at or after the cursor
+	if len(nav.GetItems()) != len(names) {
+		t.Errorf("Highlight search should keep all %d items, got %d", len(names), len(nav.GetItems()))
+	}
+	if nav.GetSelectedIndex() != 2 {
+		t.Errorf("Expected selection on gamma.go (2), got %d", nav.GetSelectedIndex())
+	}
+
+	nav.NextMatch()
+	if nav.GetSelectedIndex() != 4 {
+		t.Errorf("NextMatch expected omega.go (4), got %d", nav.GetSelectedIndex())
+	}
+	nav.NextMatch()
+	if nav.GetSelectedIndex() != 0 {
+		t.Errorf("NextMatch expected to wrap to alpha.go (0), got %d", nav.GetSelectedIndex())
+	}
+	nav.PrevMatch()
+	if nav.GetSelectedIndex() != 4 {
+		t.Errorf("PrevMatch expected to wrap to omega.go (4), got %d", nav.GetSelectedIndex())
+	}
+	nav.PrevMatch()
+	if nav.GetSelectedIndex() != 2 {
+		t.Errorf("PrevMatch expected gamma.go (2), got %d", nav.GetSelectedIndex())
+	}
+
+	// A term with a single match stays on it
+	nav.SetSearchTerm("delta")
+	nav.NextMatch()
+	if nav.GetSelectedIndex() != 3 {
+		t.Errorf("NextMatch with one match expected delta.txt (3), got %d", nav.GetSelectedIndex())
+	}
+
+	// No matches leave the selection alone
+	nav.SetSearchTerm("nomatch")
+	nav.NextMatch()
+	if nav.GetSelectedIndex() != 3 {
+		t.Errorf("NextMatch without matches moved the selection to %d", nav.GetSelectedIndex())
+	}
+}
+
+func TestGoToPath(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// Trailing slash on a file is an error and leaves the view alone
+	if err := nav.GoToPath("file1.txt/"); err == nil {
+		t.Error("GoToPath(\"file1.txt/\") expected an error for a file")
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("Failed GoToPath changed directory to %s", nav.GetCurrentPath())
+	}
+
+	// Trailing slash on a directory navigates into it
+	if err := nav.GoToPath("dir1/"); err != nil {
+		t.Fatalf("GoToPath(\"dir1/\") failed: %v", err)
+	}
+	if want := filepath.Join(tempDir, "dir1"); nav.GetCurrentPath() != want {
+		t.Errorf("GoToPath(\"dir1/\") expected %s, got %s", want, nav.GetCurrentPath())
+	}
+
+	// Without a slash, a file is revealed in its parent (absolute paths work too)
+	if err := nav.GoToPath(filepath.Join(tempDir, "file1.txt")); err != nil {
+		t.Fatalf("GoToPath to file failed: %v", err)
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("GoToPath to file expected parent %s, got %s", tempDir, nav.GetCurrentPath())
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "file1.txt" {
+		t.Errorf("GoToPath to file expected file1.txt selected, got %v", selected)
+	}
+
+	if err := nav.GoToPath("missing"); err == nil {
+		t.Error("GoToPath expected an error for a missing path")
+	}
+}
+
+func TestQuickJumps(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	home := filepath.Join(tempDir, "dir1")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	nav.MoveSelection(2)
+
+	if err := nav.GoHome(); err != nil {
+		t.Fatalf("GoHome failed: %v", err)
+	}
+	if nav.GetCurrentPath() != home {
+		t.Errorf("GoHome expected %s, got %s", home, nav.GetCurrentPath())
+	}
+
+	if err := nav.GoRoot(); err != nil {
+		t.Fatalf("GoRoot failed: %v", err)
+	}
+	if !isRoot(nav.GetCurrentPath()) {
+		t.Errorf("GoRoot expected a filesystem root, got %s", nav.GetCurrentPath())
+	}
+
+	if err := nav.GoStart(); err != nil {
+		t.Fatalf("GoStart failed: %v", err)
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("GoStart expected %s, got %s", tempDir, nav.GetCurrentPath())
+	}
+	if nav.GetSelectedIndex() != 0 {
+		t.Errorf("Expected the selection reset after a jump, got index %d", nav.GetSelectedIndex())
+	}
+
+	// Without a home the jump fails and leaves the directory alone
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	t.Setenv("home", "")
+	if err := nav.GoHome(); err == nil {
+		t.Error("GoHome expected an error without a home directory")
+	}
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("Failed GoHome changed directory to %s", nav.GetCurrentPath())
+	}
+}
+
+func TestRefreshKeepsSelection(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "file1.txt")
+
+	// A new entry sorting before the selection shifts indices
+	os.WriteFile(filepath.Join(tempDir, "aaa.txt"), []byte("content"), 0644)
+	if err := nav.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	assertContainsAll(t, nav.GetItems(), []string{"aaa.txt"})
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "file1.txt" {
+		t.Errorf("Refresh expected file1.txt to stay selected, got %v", selected)
+	}
+}
+
+func TestEnsureVisible(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	for i := 0; i < 20; i++ {
+		nav.items = append(nav.items, FileItem{Name: string(rune('a' + i))})
+	}
+	nav.filterItems()
+
+	nav.MoveSelection(12)
+	nav.EnsureVisible(5)
+	if nav.GetScrollOffset() != 8 {
+		t.Errorf("Expected offset 8 to show item 12 at the bottom, got %d", nav.GetScrollOffset())
+	}
+
+	nav.MoveSelection(-10)
+	nav.EnsureVisible(5)
+	if nav.GetScrollOffset() != 2 {
+		t.Errorf("Expected offset 2 to show item 2 at the top, got %d", nav.GetScrollOffset())
+	}
+
+	// A taller window never scrolls past the end
+	nav.MoveSelection(100)
+	nav.EnsureVisible(30)
+	if nav.GetScrollOffset() != 0 {
+		t.Errorf("Expected offset 0 when everything fits, got %d", nav.GetScrollOffset())
+	}
+}
+
 // Helper functions
+func selectByName(t *testing.T, nav *Navigator, name string) {
+	t.Helper()
+	for i, item := range nav.GetItems() {
+		if item.Name == name {
+			nav.MoveSelection(i - nav.GetSelectedIndex())
+			return
+		}
+	}
+	t.Fatalf("Item %q not found in listing", name)
+}
+
 func assertContains(t *testing.T, slice []string, item string) {
 	found := false
 	for _, s := range slice {
@@ -239,4 +1260,143 @@ func assertContainsAll(t *testing.T, items []FileItem, expectedNames []string) {
 	if foundCount != len(expectedNames) {
 		t.Errorf("Expected %d items, but found %d", len(expectedNames), foundCount)
 	}
-}
\ No newline at end of file
+}
+
+func TestNoSelectionMessage(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	cfg := nav.GetConfig()
+	cfg.HideParent = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	// An empty directory without ../ has nothing to act on
+	actions := map[string]func() error{
+		"OpenSelected":           nav.OpenSelected,
+		"OpenSelectedInTerminal": nav.OpenSelectedInTerminal,
+		"OpenNewInstance":        nav.OpenNewInstance,
+		"RenameSelected":         func() error { return nav.RenameSelected("new.txt") },
+	}
+	for name, action := range actions {
+		nav.SetStatusMessage("")
+		if err := action(); err != nil {
+			t.Errorf("%s expected no error, got %v", name, err)
+		}
+		if msg := nav.GetStatusMessage(); msg != "Nothing selected" {
+			t.Errorf("%s expected the message %q, got %q", name, "Nothing selected", msg)
+		}
+	}
+}
+
+func TestSessionRoot(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	os.Mkdir(filepath.Join(tempDir, "dir1", "inner"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "dir1")
+	if err := nav.SetSessionRoot(); err != nil {
+		t.Fatalf("SetSessionRoot failed: %v", err)
+	}
+	root := filepath.Join(tempDir, "dir1")
+	if nav.GetSessionRoot() != root || nav.GetCurrentPath() != root {
+		t.Fatalf("Expected to be rooted in %s, got root %q at %s", root, nav.GetSessionRoot(), nav.GetCurrentPath())
+	}
+	if items := nav.GetItems(); len(items) > 0 && items[0].IsParent {
+		t.Error("Expected no ../ entry at the session root")
+	}
+
+	// Going up works below the root and stops at it
+	selectByName(t, nav, "inner")
+	nav.OpenSelected()
+	nav.GoUp()
+	if nav.GetCurrentPath() != root {
+		t.Errorf("Expected to go up to the root, got %s", nav.GetCurrentPath())
+	}
+	nav.GoUp()
+	if nav.GetCurrentPath() != root {
+		t.Errorf("Expected going up to stop at the root, got %s", nav.GetCurrentPath())
+	}
+
+	nav.ClearSessionRoot()
+	nav.GoUp()
+	if nav.GetCurrentPath() != tempDir {
+		t.Errorf("Expected going up after clearing the root to reach %s, got %s", tempDir, nav.GetCurrentPath())
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Expected paths are Unix paths")
+	}
+	base := "/base"
+	tests := map[string]string{
+		"./foo//bar/":  "/base/foo/bar",
+		"foo/./bar/..": "/base/foo",
+		"/a//b/":       "/a/b",
+		"/a/../../b":   "/b",
+		"//":           "/",
+		"/":            "/",
+		".":            "/base",
+	}
+	for input, want := range tests {
+		if got := normalizePath(input, base); got != want {
+			t.Errorf("normalizePath(%q) expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+func TestIsRoot(t *testing.T) {
+	tests := map[string]bool{
+		"/":        true,
+		"//":       true,
+		"/tmp":     false,
+		"/tmp/../": true,
+	}
+	if runtime.GOOS == "windows" {
+		tests = map[string]bool{
+			`C:\`:                true,
+			`D:\`:                true,
+			`Z:\`:                true,
+			`\\server\share\`:    true,
+			`\\server\share\dir`: false,
+			`D:\Users`:           false,
+			`D:\Users\..`:        true,
+		}
+	}
+	for path, want := range tests {
+		if got := isRoot(path); got != want {
+			t.Errorf("isRoot(%q) expected %v, got %v", path, want, got)
+		}
+	}
+}
+
+func TestNavigatorNormalizesPaths(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir + "//dir1/./..//dir2/")
+	if want := filepath.Join(tempDir, "dir2"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected NewNavigator to clean the start path to %s, got %s", want, nav.GetCurrentPath())
+	}
+
+	nav.ScanDirectory()
+	if err := nav.GoToPath("..//dir1//"); err != nil {
+		t.Fatalf("GoToPath failed: %v", err)
+	}
+	if want := filepath.Join(tempDir, "dir1"); nav.GetCurrentPath() != want {
+		t.Errorf("Expected GoToPath to clean the path to %s, got %s", want, nav.GetCurrentPath())
+	}
+
+	// A messy root still counts as the root, without a ../ entry
+	if runtime.GOOS != "windows" {
+		nav, _ = NewNavigator("//.//")
+		nav.ScanDirectory()
+		if nav.GetCurrentPath() != "/" {
+			t.Errorf("Expected the root to normalize to /, got %s", nav.GetCurrentPath())
+		}
+		if items := nav.GetItems(); len(items) > 0 && items[0].IsParent {
+			t.Error("Expected no ../ entry at the normalized root")
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// EnterAction is what pressing Enter does for a file.
+type EnterAction int
+
+const (
+	ActionTerminal EnterAction = iota
+	ActionEdit
+	ActionOpen
+	ActionRun
+	ActionPreview
+)
+
+// enterActionNames are the action names used in the enter_rules setting.
+var enterActionNames = []string{
+	ActionTerminal: "terminal",
+	ActionEdit:     "edit",
+	ActionOpen:     "open",
+	ActionRun:      "run",
+	ActionPreview:  "preview",
+}
+
+// String returns the name of the action.
+func (a EnterAction) String() string {
+	return enterActionNames[a]
+}
+
+// EnterRule maps files to an action. Pattern is an extension ("md"), a mime
+// type ("text/markdown"), a mime class ("image/*"), or "*" for every file.
+type EnterRule struct {
+	Pattern string
+	Action  EnterAction
+}
+
+// parseEnterRules parses a comma-separated list of "pattern:action" rules.
+func parseEnterRules(value string) ([]EnterRule, error) {
+	var rules []EnterRule
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		// Split at the last colon so mime types with parameters stay whole
+		idx := strings.LastIndex(field, ":")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid enter rule %q: expected pattern:action", field)
+		}
+		pattern := strings.ToLower(strings.TrimSpace(field[:idx]))
+		actionName := strings.TrimSpace(field[idx+1:])
+
+		action := -1
+		for i, name := range enterActionNames {
+			if name == actionName {
+				action = i
+			}
+		}
+		if action < 0 {
+			return nil, fmt.Errorf("invalid enter action %q: expected one of %s", actionName, strings.Join(enterActionNames, ", "))
+		}
+
+		// "*.md" and ".md" are accepted as extensions too
+		if pattern != "*" && !strings.Contains(pattern, "/") {
+			pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "*"), ".")
+		}
+		rules = append(rules, EnterRule{Pattern: pattern, Action: EnterAction(action)})
+	}
+	return rules, nil
+}
+
+// parseEnterRulesSetting validates the enter_rules setting into dst.
+func parseEnterRulesSetting(value string, dst *string) error {
+	if _, err := parseEnterRules(value); err != nil {
+		return err
+	}
+	*dst = value
+	return nil
+}
+
+// resolveEnterAction picks the action for a file from its extension and mime
+// type. The most specific rule wins regardless of order: extension, then exact
+// mime type, then mime class, then "*". Without a match the file's directory
+// is opened in a terminal.
+func resolveEnterAction(rules []EnterRule, name, mimeType string) EnterAction {
+	ext := extensionOf(name)
+	class, _, _ := strings.Cut(mimeType, "/")
+	candidates := []string{ext, mimeType, class + "/*", "*"}
+	for _, candidate := range candidates {
+		if candidate == "" || candidate == "/*" {
+			continue
+		}
+		for _, rule := range rules {
+			if rule.Pattern == candidate {
+				return rule.Action
+			}
+		}
+	}
+	return ActionTerminal
+}
+
+// mimeTypeOf returns the mime type of a file, from its extension when known
+// and otherwise by sniffing its first bytes.
+func (n *Navigator) mimeTypeOf(item FileItem) string {
+	if ext := filepath.Ext(item.Name); ext != "" {
+		if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+			mimeType, _, _ = strings.Cut(mimeType, ";")
+			return strings.TrimSpace(mimeType)
+		}
+	}
+
+	file, err := n.source.Open(item.Path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	header := make([]byte, 512)
+	count, _ := file.Read(header)
+	return sniffMimeType(header[:count])
+}
+
+// sniffMimeType guesses a mime type from a file's leading bytes.
+func sniffMimeType(header []byte) string {
+	switch {
+	case len(header) == 0:
+		return "text/plain"
+	case imageFormat(header) != "":
+		return "image/" + imageFormat(header)
+	case bytes.IndexByte(header, 0) >= 0:
+		return "application/octet-stream"
+	default:
+		return "text/plain"
+	}
+}
+
+// openFile performs the configured Enter action for a file.
+func (n *Navigator) openFile(item FileItem) error {
+	rules, _ := parseEnterRules(n.config.EnterRules) // Validated when the config was loaded
+	action := ActionTerminal
+	if len(rules) > 0 {
+		action = resolveEnterAction(rules, item.Name, n.mimeTypeOf(item))
+	}
+	if action != ActionPreview && n.execDisabled() {
+		return nil
+	}
+
+	switch action {
+	case ActionEdit:
+		editor := editorCommand()
+		return n.runForeground(exec.Command(editor[0], append(editor[1:], item.Path)...))
+	case ActionOpen:
+		return n.runCommand(defaultOpenCommand(item.Path))
+	case ActionRun:
+		cmd := exec.Command(item.Path)
+		cmd.Dir = filepath.Dir(item.Path)
+		return n.runForeground(cmd)
+	case ActionPreview:
+		n.config.Preview = true
+		return nil
+	default:
+		return n.openInTerminal(item.Path, false)
+	}
+}
+
+// SetForegroundRunner sets how programs that need the terminal, such as an
+// editor, are run. The caller suspends its screen around run.
+func (n *Navigator) SetForegroundRunner(run func(cmd *exec.Cmd) error) {
+	n.runForeground = run
+}
+
+// runAttached runs cmd connected to this process's terminal and waits for it.
+func runAttached(cmd *exec.Cmd) error {
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR, split into
+// the program and its arguments.
+func editorCommand() []string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(key)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// bundleExtensions are directory extensions macOS presents as a single item:
+// applications, plug-ins and package documents.
+var bundleExtensions = []string{
+	"app", "bundle", "framework", "plugin", "kext", "prefPane",
+	"rtfd", "pages", "numbers", "key",
+}
+
+// isBundle reports whether a directory called name is a macOS bundle.
+func isBundle(name string) bool {
+	ext := extensionOf(strings.TrimSuffix(name, "/"))
+	for _, bundleExt := range bundleExtensions {
+		if strings.EqualFold(ext, bundleExt) {
+			return true
+		}
+	}
+	return false
+}
+
+// opensAsBundle reports whether Enter opens item with the default app instead
+// of descending into it, which only happens for bundles on macOS.
+func opensAsBundle(item FileItem, goos string) bool {
+	return goos == "darwin" && item.IsDir && isBundle(item.Name)
+}
+
+// defaultOpenCommand opens path with the desktop's default application.
+func defaultOpenCommand(path string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", path)
+	case "windows":
+		return exec.Command("cmd", "/c", "start", "", path)
+	default:
+		return exec.Command("xdg-open", path)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEnterRules(t *testing.T) {
+	rules, err := parseEnterRules("md:edit, *.sh:run, .txt:preview, image/*:open, *:terminal")
+	if err != nil {
+		t.Fatalf("parseEnterRules failed: %v", err)
+	}
+	want := []EnterRule{
+		{"md", ActionEdit},
+		{"sh", ActionRun},
+		{"txt", ActionPreview},
+		{"image/*", ActionOpen},
+		{"*", ActionTerminal},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("Expected %d rules, got %d", len(want), len(rules))
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("Rule %d expected %+v, got %+v", i, want[i], rules[i])
+		}
+	}
+
+	for _, value := range []string{"md", "md:launch", ":edit"} {
+		if _, err := parseEnterRules(value); err == nil {
+			t.Errorf("parseEnterRules(%q) expected an error", value)
+		}
+	}
+}
+
+func TestResolveEnterActionPrecedence(t *testing.T) {
+	// Listed least specific first to show that order does not matter
+	rules := []EnterRule{
+		{"*", ActionOpen},
+		{"text/*", ActionPreview},
+		{"text/markdown", ActionRun},
+		{"md", ActionEdit},
+	}
+	tests := []struct {
+		name, mimeType string
+		want           EnterAction
+	}{
+		{"notes.md", "text/markdown", ActionEdit},      // Extension beats mime type
+		{"notes.markdown", "text/markdown", ActionRun}, // Mime type beats class
+		{"main.go", "text/plain", ActionPreview},       // Class beats catch-all
+		{"photo.png", "image/png", ActionOpen},         // Catch-all
+		{"Makefile", "", ActionOpen},                   // No extension or mime type
+	}
+	for _, tt := range tests {
+		if got := resolveEnterAction(rules, tt.name, tt.mimeType); got != tt.want {
+			t.Errorf("resolveEnterAction(%q, %q) expected %s, got %s", tt.name, tt.mimeType, tt.want, got)
+		}
+	}
+
+	// Without a matching rule the parent opens in a terminal
+	if got := resolveEnterAction([]EnterRule{{"md", ActionEdit}}, "main.go", "text/plain"); got != ActionTerminal {
+		t.Errorf("Expected the terminal fallback, got %s", got)
+	}
+	if got := resolveEnterAction(nil, "notes.md", "text/markdown"); got != ActionTerminal {
+		t.Errorf("Expected the terminal fallback without rules, got %s", got)
+	}
+}
+
+func TestSniffMimeType(t *testing.T) {
+	tests := map[string]string{
+		"":                      "text/plain",
+		"#!/bin/sh\necho hi\n":  "text/plain",
+		"\x89PNG\r\n\x1a\nrest": "image/png",
+		"ELF\x00\x01":           "application/octet-stream",
+	}
+	for header, want := range tests {
+		if got := sniffMimeType([]byte(header)); got != want {
+			t.Errorf("sniffMimeType(%q) expected %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestOpenFileDispatch(t *testing.T) {
+	t.Setenv("TERMINAL", "fake-terminal")
+	t.Setenv("VISUAL", "fake-editor -w")
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "notes.md"), []byte("# notes"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "build"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.EnterRules = "md:edit, text/plain:run"
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	var background, foreground []*exec.Cmd
+	nav.runCommand = func(cmd *exec.Cmd) error {
+		background = append(background, cmd)
+		return nil
+	}
+	nav.SetForegroundRunner(func(cmd *exec.Cmd) error {
+		foreground = append(foreground, cmd)
+		return nil
+	})
+
+	selectByName(t, nav, "notes.md")
+	nav.OpenSelected()
+	if len(foreground) != 1 || foreground[0].Args[0] != "fake-editor" || foreground[0].Args[2] != filepath.Join(tempDir, "notes.md") {
+		t.Errorf("Expected notes.md in the editor, got %v", foreground)
+	}
+
+	// No extension: the sniffed text/plain type picks "run"
+	selectByName(t, nav, "build")
+	nav.OpenSelected()
+	if len(foreground) != 2 || foreground[1].Path != filepath.Join(tempDir, "build") {
+		t.Errorf("Expected build to be run, got %v", foreground)
+	}
+
+	// .go has no rule (and usually no known mime type), so the terminal opens
+	selectByName(t, nav, "main.go")
+	nav.OpenSelected()
+	if len(background) != 1 || filepath.Base(background[0].Path) != "fake-terminal" {
+		t.Errorf("Expected the terminal fallback for main.go, got %v", background)
+	}
+}
+
+func TestNoExec(t *testing.T) {
+	t.Setenv("TERMINAL", "fake-terminal")
+	tempDir := t.TempDir()
+	for _, name := range []string{"notes.md", "photo.png", "build.sh", "main.go"} {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("content"), 0644)
+	}
+	os.Mkdir(filepath.Join(tempDir, "src"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.NoExec = true
+	cfg.EnterRules = "md:edit, png:open, sh:run, *:terminal"
+	cfg.OnSelect = "tmux send-keys"
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	nav.onSelect = &debouncer{delay: time.Millisecond}
+
+	var started []*exec.Cmd
+	nav.runCommand = func(cmd *exec.Cmd) error {
+		started = append(started, cmd)
+		return nil
+	}
+	nav.SetForegroundRunner(func(cmd *exec.Cmd) error {
+		started = append(started, cmd)
+		return nil
+	})
+
+	actions := map[string]func() error{
+		"OpenSelected":           nav.OpenSelected,
+		"OpenSelectedInTerminal": nav.OpenSelectedInTerminal,
+		"OpenNewInstance":        nav.OpenNewInstance,
+	}
+	for _, name := range []string{"notes.md", "photo.png", "build.sh", "main.go"} {
+		for label, action := range actions {
+			selectByName(t, nav, name)
+			nav.SetStatusMessage("")
+			if err := action(); err != nil {
+				t.Errorf("%s on %s failed: %v", label, name, err)
+			}
+			if got := nav.GetStatusMessage(); !strings.Contains(got, "disabled") {
+				t.Errorf("%s on %s expected a disabled message, got %q", label, name, got)
+			}
+		}
+	}
+
+	selectByName(t, nav, "src")
+	nav.ToggleMark()
+	if err := nav.OpenMarkedInTerminal(); err != nil {
+		t.Errorf("OpenMarkedInTerminal failed: %v", err)
+	}
+	nav.NotifySelection()
+	time.Sleep(20 * time.Millisecond)
+
+	if len(started) > 0 {
+		t.Errorf("Expected nothing to be started with --no-exec, got %v", started)
+	}
+}
+
+func TestIsBundle(t *testing.T) {
+	tests := map[string]bool{
+		"Safari.app":        true,
+		"Safari.app/":       true,
+		"Plugin.bundle":     true,
+		"Cocoa.framework":   true,
+		"Report.pages":      true,
+		"Notes.rtfd":        true,
+		"Display.prefpane":  true,
+		"LOUD.APP":          true,
+		"src":               false,
+		"app":               false,
+		".app":              false,
+		"backup.app.tar.gz": false,
+	}
+	for name, want := range tests {
+		if got := isBundle(name); got != want {
+			t.Errorf("isBundle(%q) expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestOpensAsBundle(t *testing.T) {
+	bundle := FileItem{Name: "Safari.app", IsDir: true}
+	if !opensAsBundle(bundle, "darwin") {
+		t.Error("Expected a .app directory to open as a bundle on macOS")
+	}
+	if opensAsBundle(bundle, "linux") {
+		t.Error("Expected bundles to be plain directories elsewhere")
+	}
+	if opensAsBundle(FileItem{Name: "notes.key"}, "darwin") {
+		t.Error("Expected a file with a bundle extension not to be a bundle")
+	}
+}
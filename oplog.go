@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// opLogEntry is one line of the operation log, written as JSON.
+type opLogEntry struct {
+	Time   time.Time `json:"time"`
+	Op     string    `json:"op"`
+	Paths  []string  `json:"paths"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// logOperation records a successful mutating operation in the operation log,
+// when one is configured. Failures go to the status bar, never to stdout or
+// stderr, so the TUI is left alone.
+func (n *Navigator) logOperation(op, detail string, paths ...string) {
+	if n.config.OpLogPath == "" {
+		return
+	}
+	entry := opLogEntry{Time: time.Now().UTC(), Op: op, Paths: paths, Detail: detail}
+	if err := appendOpLog(n.config.OpLogPath, entry); err != nil {
+		n.SetStatusMessage(fmt.Sprintf("Operation log failed: %v", err))
+	}
+}
+
+// appendOpLog appends entry as a single JSON line to the log at path.
+func appendOpLog(path string, entry opLogEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// readOpLog returns the entries written to the log at path.
+func readOpLog(t *testing.T, path string) []opLogEntry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the operation log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []opLogEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry opLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid log line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRenameIsLogged(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	logPath := filepath.Join(t.TempDir(), "ops.log")
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.OpLogPath = logPath
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	before := time.Now().Add(-time.Second)
+	selectByName(t, nav, "file1.txt")
+	if err := nav.RenameSelected("renamed.txt"); err != nil {
+		t.Fatalf("RenameSelected failed: %v", err)
+	}
+
+	entries := readOpLog(t, logPath)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	want := []string{filepath.Join(tempDir, "file1.txt"), filepath.Join(tempDir, "renamed.txt")}
+	if entry.Op != "rename" || !reflect.DeepEqual(entry.Paths, want) {
+		t.Errorf("Expected rename of %v, got %s %v", want, entry.Op, entry.Paths)
+	}
+	if entry.Time.Before(before) || entry.Time.After(time.Now()) {
+		t.Errorf("Expected a current timestamp, got %v", entry.Time)
+	}
+
+	// Failed operations are not logged
+	selectByName(t, nav, "renamed.txt")
+	if err := nav.RenameSelected("dir1"); err == nil {
+		t.Error("Expected renaming onto an existing entry to fail")
+	}
+	if entries := readOpLog(t, logPath); len(entries) != 1 {
+		t.Errorf("Expected the failed rename to be left out of the log, got %d entries", len(entries))
+	}
+}
+
+func TestOpLogDisabled(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "file1.txt")
+	if err := nav.RenameSelected("renamed.txt"); err != nil {
+		t.Fatalf("RenameSelected failed: %v", err)
+	}
+	if msg := nav.GetStatusMessage(); msg != "" {
+		t.Errorf("Expected no status message without a log, got %q", msg)
+	}
+}
+
+func TestOpLogFailureGoesToStatusBar(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.OpLogPath = filepath.Join(tempDir, "missing", "ops.log")
+	nav.SetConfig(cfg)
+	nav.logOperation("rename", "", "a", "b")
+	if nav.GetStatusMessage() == "" {
+		t.Error("Expected a status message when the log cannot be written")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SetOutput sets the writer that EmitSelected sends paths to.
+func (n *Navigator) SetOutput(w io.Writer) {
+	n.output = w
+}
+
+// EmitSelected writes the selected item's path and a newline to the output
+// writer, without leaving nav.
+func (n *Navigator) EmitSelected() error {
+	if n.output == nil {
+		return errors.New("no output target (start nav with --output PATH)")
+	}
+	selectedItem := n.requireSelection()
+	if selectedItem == nil {
+		return nil
+	}
+	_, err := fmt.Fprintln(n.output, selectedItem.Path)
+	return err
+}
+
+// outputTimeout is how long a write to a FIFO may wait for its reader to make
+// room before it is given up.
+const outputTimeout = 2 * time.Second
+
+// appendWriter appends each write to a file or FIFO, reopening it every time so
+// readers can come and go while nav keeps running.
+type appendWriter struct {
+	path string
+}
+
+// Write appends p to the target path.
+func (w appendWriter) Write(p []byte) (int, error) {
+	file, err := openOutput(w.path)
+	if err != nil {
+		return 0, err
+	}
+	// Only pipes support deadlines; regular files do not block anyway
+	file.SetWriteDeadline(time.Now().Add(outputTimeout))
+	count, err := file.Write(p)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return count, err
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// openOutput opens path for appending.
+func openOutput(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
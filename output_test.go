@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmitSelected(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	if err := nav.EmitSelected(); err == nil {
+		t.Error("EmitSelected without an output writer expected an error")
+	}
+
+	var buf bytes.Buffer
+	nav.SetOutput(&buf)
+	selectByName(t, nav, "file1.txt")
+	if err := nav.EmitSelected(); err != nil {
+		t.Fatalf("EmitSelected failed: %v", err)
+	}
+	selectByName(t, nav, "dir1")
+	nav.EmitSelected()
+
+	want := filepath.Join(tempDir, "file1.txt") + "\n" + filepath.Join(tempDir, "dir1") + "\n"
+	if buf.String() != want {
+		t.Errorf("EmitSelected wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEmitSelectedWriteError(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	nav.SetOutput(failingWriter{})
+	if err := nav.EmitSelected(); err == nil {
+		t.Error("EmitSelected expected the writer's error")
+	}
+}
+
+func TestAppendWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selected")
+	w := appendWriter{path: path}
+	w.Write([]byte("one\n"))
+	w.Write([]byte("two\n"))
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "one\ntwo\n" {
+		t.Errorf("appendWriter produced %q (err %v)", data, err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
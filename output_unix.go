@@ -0,0 +1,20 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// openOutput opens path for appending. It does not block, so with a FIFO
+// that has no reader the open fails at once instead of hanging nav.
+func openOutput(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|syscall.O_NONBLOCK, 0644)
+	if errors.Is(err, syscall.ENXIO) {
+		return nil, fmt.Errorf("no reader on %s", path)
+	}
+	return file, err
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAppendWriterFIFO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selected.fifo")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skipf("FIFOs unsupported: %v", err)
+	}
+	w := appendWriter{path: path}
+
+	// Without a reader the write fails at once instead of blocking
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Write([]byte("one\n"))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "no reader") {
+			t.Errorf("Expected a no reader error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Write to a FIFO without a reader blocked")
+	}
+
+	reader, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if _, err := w.Write([]byte("two\n")); err != nil {
+		t.Fatalf("Write with a reader failed: %v", err)
+	}
+	buf := make([]byte, 16)
+	count, _ := reader.Read(buf)
+	if got := string(buf[:count]); got != "two\n" {
+		t.Errorf("Expected the reader to get %q, got %q", "two\n", got)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	maxPreviewLines = 500
+	maxPreviewBytes = 64 * 1024
+)
+
+// TogglePreview shows or hides the preview pane.
+func (n *Navigator) TogglePreview() {
+	n.config.Preview = !n.config.Preview
+}
+
+// TogglePreviewWrap switches the preview between wrapping and clipping long lines.
+func (n *Navigator) TogglePreviewWrap() {
+	n.config.PreviewWrap = !n.config.PreviewWrap
+}
+
+const (
+	// minSplit and maxSplit bound the share of the width given to the list
+	minSplit = 20
+	maxSplit = 80
+	// minPaneWidth keeps both panes usable on narrow terminals
+	minPaneWidth = 12
+)
+
+// ResizePreview grows the list by delta percent of the width, shrinking the
+// preview, and returns the new split.
+func (n *Navigator) ResizePreview(delta int) int {
+	n.config.PreviewSplit = clampSplit(n.config.PreviewSplit + delta)
+	return n.config.PreviewSplit
+}
+
+// clampSplit limits a split percentage to the supported range.
+func clampSplit(split int) int {
+	return min(max(split, minSplit), maxSplit)
+}
+
+// previewX returns the column where the preview pane starts on a screen w
+// wide, giving the list split percent of it. Both panes keep at least
+// minPaneWidth columns when the screen allows.
+func previewX(w, split int) int {
+	x := w * clampSplit(split) / 100
+	if w < 2*minPaneWidth {
+		return w / 2
+	}
+	return min(max(x, minPaneWidth), w-minPaneWidth)
+}
+
+// PreviewSelected returns the lines to show in the preview pane for the selected
+// item: the start of a text file, or the entries of a directory.
+func (n *Navigator) PreviewSelected() ([]string, error) {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil {
+		return nil, nil
+	}
+	if selectedItem.Path == n.previewPath {
+		return n.previewLines, nil
+	}
+
+	var lines []string
+	var err error
+	limit := n.config.PreviewMaxSize
+	if selectedItem.IsDir {
+		lines, err = previewDirectory(n.source, selectedItem.Path)
+	} else if size := n.withMetadata(*selectedItem).Size; limit > 0 && size > limit {
+		// Skip reading the start of huge files such as logs
+		lines = []string{fmt.Sprintf("File too large to preview (%s, limit %s)", formatSize(size), formatSize(limit))}
+	} else {
+		lines, err = previewFile(n.source, selectedItem.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n.previewPath = selectedItem.Path
+	n.previewLines = lines
+	return lines, nil
+}
+
+// previewDirectory lists the names of the entries in a directory.
+func previewDirectory(source FileSource, path string) ([]string, error) {
+	entries, err := source.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name
+		if entry.IsDir {
+			name += "/"
+		}
+		lines = append(lines, name)
+		if len(lines) >= maxPreviewLines {
+			break
+		}
+	}
+	return lines, nil
+}
+
+// previewFile reads the first lines of a file, refusing binary content and
+// anything that is not a regular file.
+func previewFile(source FileSource, path string) ([]string, error) {
+	// Stat follows symlinks, so a link to a FIFO is refused too
+	info, err := source.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireRegular(filepath.Base(path), info.Mode()); err != nil {
+		return nil, err
+	}
+
+	file, err := source.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, maxPreviewBytes)
+	count, err := file.Read(buf)
+	if err != nil && count == 0 {
+		if err == io.EOF {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	buf = buf[:count]
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return []string{"(binary file)"}, nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	scanner.Buffer(make([]byte, 0, maxPreviewBytes), maxPreviewBytes)
+	for scanner.Scan() && len(lines) < maxPreviewLines {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return lines, nil
+}
+
+// expandTabs replaces tabs with spaces up to the next multiple of tabWidth columns.
+func expandTabs(line string, tabWidth int) string {
+	if tabWidth <= 0 || !strings.Contains(line, "\t") {
+		return line
+	}
+	var sb strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := tabWidth - col%tabWidth
+			sb.WriteString(strings.Repeat(" ", spaces))
+			col += spaces
+			continue
+		}
+		sb.WriteRune(r)
+		col += runewidth.RuneWidth(r)
+	}
+	return sb.String()
+}
+
+// wrapLines fits preview lines to width display columns, either wrapping long
+// lines onto following rows or clipping them.
+func wrapLines(lines []string, width, tabWidth int, wrap bool) []string {
+	if width <= 0 {
+		return nil
+	}
+	var result []string
+	for _, line := range lines {
+		line = expandTabs(line, tabWidth)
+		if !wrap {
+			result = append(result, runewidth.Truncate(line, width, ""))
+			continue
+		}
+
+		var current strings.Builder
+		currentWidth := 0
+		for _, r := range line {
+			rw := runewidth.RuneWidth(r)
+			if currentWidth+rw > width {
+				result = append(result, current.String())
+				current.Reset()
+				currentWidth = 0
+			}
+			current.WriteRune(r)
+			currentWidth += rw
+		}
+		result = append(result, current.String())
+	}
+	return result
+}
+
+// FocusPreview moves the keyboard into the preview pane, with a line cursor on
+// its first line, so a range of the loaded lines can be copied. It does
+// nothing when the pane is hidden or has no text.
+func (n *Navigator) FocusPreview() {
+	if !n.config.Preview {
+		n.SetStatusMessage("Preview is hidden (p shows it)")
+		return
+	}
+	if _, ok := n.PreviewImage(); ok {
+		return
+	}
+	if lines, err := n.PreviewSelected(); err != nil || len(lines) == 0 {
+		n.SetStatusMessage("Nothing to copy in the preview")
+		return
+	}
+	n.previewFocus = true
+	n.previewCursor = 0
+	n.previewAnchor = -1
+}
+
+// UnfocusPreview returns the keyboard to the listing.
+func (n *Navigator) UnfocusPreview() {
+	n.previewFocus = false
+}
+
+// IsPreviewFocused reports whether keys go to the preview pane.
+func (n *Navigator) IsPreviewFocused() bool {
+	return n.previewFocus
+}
+
+// MovePreviewCursor moves the preview cursor by delta lines, staying within
+// the loaded lines.
+func (n *Navigator) MovePreviewCursor(delta int) {
+	n.previewCursor = min(max(n.previewCursor+delta, 0), max(len(n.previewLines)-1, 0))
+}
+
+// TogglePreviewRange starts a range at the cursor, or drops the started one.
+func (n *Navigator) TogglePreviewRange() {
+	if n.previewAnchor >= 0 {
+		n.previewAnchor = -1
+		return
+	}
+	n.previewAnchor = n.previewCursor
+}
+
+// PreviewCursor returns the cursor line and the range to copy, which is just
+// the cursor line until a range is started.
+func (n *Navigator) PreviewCursor() (cursor, start, end int) {
+	start, end = n.previewCursor, n.previewCursor
+	if n.previewAnchor >= 0 {
+		start, end = min(n.previewAnchor, n.previewCursor), max(n.previewAnchor, n.previewCursor)
+	}
+	return n.previewCursor, start, end
+}
+
+// CopyPreviewRange copies the selected preview lines to the clipboard, returns
+// the keyboard to the listing and reports how many lines were copied.
+func (n *Navigator) CopyPreviewRange() (int, error) {
+	_, start, end := n.PreviewCursor()
+	lines := previewRange(n.previewLines, start, end)
+	if len(lines) == 0 {
+		return 0, nil
+	}
+	if err := n.clipboard(strings.Join(lines, "\n") + "\n"); err != nil {
+		return 0, err
+	}
+	n.previewFocus = false
+	return len(lines), nil
+}
+
+// previewRange returns lines start through end, inclusive and in either
+// order, clamped to the lines available.
+func previewRange(lines []string, start, end int) []string {
+	if start > end {
+		start, end = end, start
+	}
+	start = max(start, 0)
+	end = min(end, len(lines)-1)
+	if start > end {
+		return nil
+	}
+	return lines[start : end+1]
+}
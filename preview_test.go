@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWrapLongLine(t *testing.T) {
+	got := wrapLines([]string{"abcdefghij", "abc"}, 4, 4, true)
+	want := []string{"abcd", "efgh", "ij", "abc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapLines = %q, want %q", got, want)
+	}
+
+	// Wide runes never straddle a row boundary
+	got = wrapLines([]string{"a世界"}, 4, 4, true)
+	want = []string{"a世", "界"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapLines with wide runes = %q, want %q", got, want)
+	}
+
+	// Empty lines are kept
+	got = wrapLines([]string{""}, 4, 4, true)
+	if !reflect.DeepEqual(got, []string{""}) {
+		t.Errorf("wrapLines dropped an empty line: %q", got)
+	}
+}
+
+func TestClipMode(t *testing.T) {
+	got := wrapLines([]string{"abcdefghij", "ab"}, 4, 4, false)
+	want := []string{"abcd", "ab"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapLines in clip mode = %q, want %q", got, want)
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		line     string
+		tabWidth int
+		want     string
+	}{
+		{"\tx", 4, "    x"},
+		{"ab\tx", 4, "ab  x"},
+		{"abcd\tx", 4, "abcd    x"},
+		{"a\tb\tc", 2, "a b c"},
+		{"\tx", 8, "        x"},
+		{"no tabs", 4, "no tabs"},
+	}
+	for _, tt := range tests {
+		if got := expandTabs(tt.line, tt.tabWidth); got != tt.want {
+			t.Errorf("expandTabs(%q, %d) = %q, want %q", tt.line, tt.tabWidth, got, tt.want)
+		}
+	}
+
+	// Tabs are expanded before wrapping
+	got := wrapLines([]string{"\tabcd"}, 6, 4, true)
+	if want := []string{"    ab", "cd"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapLines with tabs = %q, want %q", got, want)
+	}
+}
+
+func TestPreviewSelected(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("first\r\nsecond\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "data.bin"), []byte{0x7f, 0x00, 0x01}, 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	selectByName(t, nav, "notes.txt")
+	lines, err := nav.PreviewSelected()
+	if err != nil {
+		t.Fatalf("PreviewSelected failed: %v", err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("PreviewSelected = %q, want %q", lines, want)
+	}
+
+	selectByName(t, nav, "data.bin")
+	lines, _ = nav.PreviewSelected()
+	if !reflect.DeepEqual(lines, []string{"(binary file)"}) {
+		t.Errorf("PreviewSelected for binary file = %q", lines)
+	}
+}
+
+func TestPreviewMaxSize(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "big.log"), bytes.Repeat([]byte("x\n"), 1024), 0644)
+	os.WriteFile(filepath.Join(tempDir, "small.txt"), []byte("hello\n"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.PreviewMaxSize = 1024
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	selectByName(t, nav, "big.log")
+	lines, err := nav.PreviewSelected()
+	if err != nil {
+		t.Fatalf("PreviewSelected failed: %v", err)
+	}
+	if want := []string{"File too large to preview (2.0K, limit 1.0K)"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("PreviewSelected over the limit = %q, want %q", lines, want)
+	}
+
+	selectByName(t, nav, "small.txt")
+	lines, _ = nav.PreviewSelected()
+	if want := []string{"hello"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("PreviewSelected under the limit = %q, want %q", lines, want)
+	}
+}
+
+func TestPreviewRange(t *testing.T) {
+	lines := []string{"one", "two", "three", "four"}
+	tests := []struct {
+		start, end int
+		want       []string
+	}{
+		{1, 2, []string{"two", "three"}},
+		{2, 1, []string{"two", "three"}}, // Either order
+		{3, 3, []string{"four"}},
+		{2, 9, []string{"three", "four"}}, // Clamped to the buffer
+		{-1, 0, []string{"one"}},
+		{5, 7, nil},
+	}
+	for _, tt := range tests {
+		if got := previewRange(lines, tt.start, tt.end); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("previewRange(%d, %d) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestCopyPreviewRange(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("a\nb\nc\nd\n"), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.Preview = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	var copied string
+	nav.clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+
+	selectByName(t, nav, "notes.txt")
+	nav.FocusPreview()
+	if !nav.IsPreviewFocused() {
+		t.Fatal("Expected the preview to take focus")
+	}
+	nav.MovePreviewCursor(1)
+	nav.TogglePreviewRange()
+	nav.MovePreviewCursor(5) // Stops at the last line
+	count, err := nav.CopyPreviewRange()
+	if err != nil || count != 3 || copied != "b\nc\nd\n" {
+		t.Errorf("Expected lines b-d copied, got %d lines %q (err %v)", count, copied, err)
+	}
+	if nav.IsPreviewFocused() {
+		t.Error("Expected copying to return focus to the listing")
+	}
+}
+
+func TestResizePreview(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	if got := nav.ResizePreview(5); got != 55 {
+		t.Errorf("Expected the split to grow to 55, got %d", got)
+	}
+	for i := 0; i < 20; i++ {
+		nav.ResizePreview(5)
+	}
+	if got := nav.GetConfig().PreviewSplit; got != maxSplit {
+		t.Errorf("Expected the split to stop at %d, got %d", maxSplit, got)
+	}
+	for i := 0; i < 20; i++ {
+		nav.ResizePreview(-5)
+	}
+	if got := nav.GetConfig().PreviewSplit; got != minSplit {
+		t.Errorf("Expected the split to stop at %d, got %d", minSplit, got)
+	}
+}
+
+func TestPreviewX(t *testing.T) {
+	tests := []struct {
+		width, split, want int
+	}{
+		{100, 50, 50},
+		{100, 70, 70},
+		{100, 95, 80}, // Clamped to the maximum split
+		{40, 20, 12},  // The list keeps minPaneWidth columns
+		{40, 80, 28},  // So does the preview
+		{20, 70, 10},  // Too narrow for both minimums: halved
+	}
+	for _, tt := range tests {
+		if got := previewX(tt.width, tt.split); got != tt.want {
+			t.Errorf("previewX(%d, %d) expected %d, got %d", tt.width, tt.split, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"time"
+)
+
+// refreshEvent asks the main loop to rescan the current directory.
+type refreshEvent struct {
+	when time.Time
+}
+
+// When returns the time the refresh was requested.
+func (e *refreshEvent) When() time.Time {
+	return e.when
+}
+
+// resultEvent hands a result computed in the background to the main loop,
+// which calls apply.
+type resultEvent struct {
+	when  time.Time
+	apply func()
+}
+
+// When returns the time the result was posted.
+func (e *resultEvent) When() time.Time {
+	return e.when
+}
+
+// startAutoRefresh calls post every interval until the returned stop function
+// is called. A zero or negative interval disables refreshing.
+func startAutoRefresh(interval time.Duration, post func()) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				post()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
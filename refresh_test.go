@@ -0,0 +1,42 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartAutoRefresh(t *testing.T) {
+	var posts atomic.Int32
+	stop := startAutoRefresh(5*time.Millisecond, func() {
+		posts.Add(1)
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for posts.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	if posts.Load() < 3 {
+		t.Fatalf("Expected at least 3 refreshes within a second, got %d", posts.Load())
+	}
+
+	// No more refreshes are posted after stopping
+	stopped := posts.Load()
+	time.Sleep(20 * time.Millisecond)
+	if posts.Load() != stopped {
+		t.Errorf("Refresh posted after stop: %d -> %d", stopped, posts.Load())
+	}
+}
+
+func TestStartAutoRefreshDisabled(t *testing.T) {
+	var posts atomic.Int32
+	stop := startAutoRefresh(0, func() {
+		posts.Add(1)
+	})
+	time.Sleep(10 * time.Millisecond)
+	stop()
+	if posts.Load() != 0 {
+		t.Errorf("Zero interval should disable refreshing, got %d posts", posts.Load())
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/sftp"
+)
+
+// remoteSource is implemented by sources on another machine. Their paths
+// mean nothing locally, so the Navigator neither writes to them nor hands
+// them to programs.
+type remoteSource interface {
+	FileSource
+	// Home returns the remote login directory.
+	Home() string
+}
+
+// errRemoteReadOnly is returned by operations that would change files while
+// browsing a remote source.
+var errRemoteReadOnly = errors.New("remote directories are read-only")
+
+// remoteArg describes an sftp://[user@]host[:port]/path start path.
+type remoteArg struct {
+	Dest string // [user@]host, as passed to ssh
+	Port string
+	Path string // Empty for the login directory
+}
+
+// parseRemoteArg parses an sftp:// or ssh:// start path. It reports false for
+// anything else, which is taken as a local path. A path starting with /~ is
+// relative to the login directory, as sftp and scp clients treat it.
+func parseRemoteArg(arg string) (remoteArg, bool, error) {
+	u, err := url.Parse(arg)
+	if err != nil || (u.Scheme != "sftp" && u.Scheme != "ssh") {
+		return remoteArg{}, false, nil
+	}
+	if u.Hostname() == "" {
+		return remoteArg{}, true, fmt.Errorf("%s: expected %s://[user@]host[:port]/path", arg, u.Scheme)
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return remoteArg{}, true, fmt.Errorf("%s://%s: passwords in the URL are not supported; ssh asks for one when needed", u.Scheme, u.Hostname())
+	}
+	remote := remoteArg{Dest: u.Hostname(), Port: u.Port(), Path: u.Path}
+	if user := u.User.Username(); user != "" {
+		remote.Dest = user + "@" + remote.Dest
+	}
+	if remote.Path == "/" || remote.Path == "/~" {
+		remote.Path = ""
+	} else if rest, ok := strings.CutPrefix(remote.Path, "/~/"); ok {
+		remote.Path = rest
+	}
+	return remote, true, nil
+}
+
+// sftpSource reads from another machine over SFTP, read-only. The connection
+// runs through the system's ssh client, so its configuration, keys, agent
+// and known hosts all apply.
+type sftpSource struct {
+	client *sftp.Client
+	home   string
+	cmd    *exec.Cmd  // The ssh process carrying the session, if any
+	stderr *sshStderr // What ssh writes to its stderr
+}
+
+// dialSFTP starts an SFTP session with remote through ssh. ssh asks for
+// passwords on the terminal directly, so it can do that before the screen is
+// set up. Its stderr is kept off the screen: a failure to connect includes
+// it, and later messages go to ReportStderr.
+func dialSFTP(remote remoteArg) (*sftpSource, error) {
+	args := []string{"-s"}
+	if remote.Port != "" {
+		args = append(args, "-p", remote.Port)
+	}
+	cmd := exec.Command("ssh", append(args, remote.Dest, "sftp")...)
+	stderr := &sshStderr{}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start ssh: %w", err)
+	}
+	client, err := sftp.NewClientPipe(stdout, stdin)
+	if err != nil {
+		stdin.Close()
+		cmd.Wait()
+		if msg := stderr.String(); msg != "" {
+			return nil, fmt.Errorf("%s: cannot start an SFTP session: %w\n%s", remote.Dest, err, msg)
+		}
+		return nil, fmt.Errorf("%s: cannot start an SFTP session: %w", remote.Dest, err)
+	}
+	source, err := newSFTPSource(client)
+	if err != nil {
+		client.Close()
+		cmd.Wait()
+		return nil, err
+	}
+	source.cmd, source.stderr = cmd, stderr
+	return source, nil
+}
+
+// ReportStderr hands each line ssh wrote to its stderr to report, first those
+// written while connecting and then any that follow. report is called from
+// the goroutine copying ssh's output.
+func (s *sftpSource) ReportStderr(report func(line string)) {
+	if s.stderr != nil {
+		s.stderr.setReport(report)
+	}
+}
+
+// maxStderrLines bounds how many lines of ssh's stderr are held until
+// ReportStderr is called.
+const maxStderrLines = 20
+
+// sshStderr collects ssh's stderr by line, holding the lines until report is
+// set.
+type sshStderr struct {
+	mu      sync.Mutex
+	partial []byte
+	lines   []string
+	report  func(line string)
+}
+
+func (w *sshStderr) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSpace(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+		switch {
+		case line == "":
+		case w.report != nil:
+			w.report(line)
+		case len(w.lines) < maxStderrLines:
+			w.lines = append(w.lines, line)
+		}
+	}
+	return len(p), nil
+}
+
+// setReport passes the held lines to report and every later one.
+func (w *sshStderr) setReport(report func(line string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, line := range w.lines {
+		report(line)
+	}
+	w.lines, w.report = nil, report
+}
+
+// String returns the held lines, and any unfinished one, for an error.
+func (w *sshStderr) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lines := w.lines
+	if partial := strings.TrimSpace(string(w.partial)); partial != "" {
+		lines = append(lines, partial)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newSFTPSource wraps an SFTP session, asking the server for the login
+// directory.
+func newSFTPSource(client *sftp.Client) (*sftpSource, error) {
+	home, err := client.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("cannot find the remote login directory: %w", err)
+	}
+	return &sftpSource{client: client, home: path.Clean(home)}, nil
+}
+
+// Resolve returns the local form of the remote path p, relative paths taken
+// from the login directory, for starting the Navigator there.
+func (s *sftpSource) Resolve(p string) string {
+	if p == "" || !path.IsAbs(p) {
+		p = path.Join(s.home, p)
+	}
+	return filepath.FromSlash(path.Clean(p))
+}
+
+// remote converts a Navigator path back to the server's slash-separated
+// form. On Windows the drive letter that filepath.Abs adds is dropped.
+func remote(p string) string {
+	return filepath.ToSlash(strings.TrimPrefix(p, filepath.VolumeName(p)))
+}
+
+// ReadDir lists p, stat'ing symlinks on the server to learn which point at
+// directories.
+func (s *sftpSource) ReadDir(p string) ([]FileItem, error) {
+	infos, err := s.client.ReadDir(remote(p))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	items := itemsFromEntries(p, entries)
+	for i := range items {
+		if items[i].Type == TypeSymlink {
+			info, err := s.client.Stat(remote(items[i].Path))
+			items[i].LinkDir = err == nil && info.IsDir()
+		}
+	}
+	return items, nil
+}
+
+// Stat stats p on the server, following symlinks.
+func (s *sftpSource) Stat(p string) (fs.FileInfo, error) {
+	return s.client.Stat(remote(p))
+}
+
+// Open opens p on the server for reading.
+func (s *sftpSource) Open(p string) (io.ReadCloser, error) {
+	return s.client.Open(remote(p))
+}
+
+// Readlink returns the target of the symlink p on the server.
+func (s *sftpSource) Readlink(p string) (string, error) {
+	return s.client.ReadLink(remote(p))
+}
+
+// Home returns the remote login directory.
+func (s *sftpSource) Home() string {
+	return filepath.FromSlash(s.home)
+}
+
+// Close ends the session and waits for ssh to exit.
+func (s *sftpSource) Close() error {
+	err := s.client.Close()
+	if s.cmd != nil {
+		s.cmd.Wait()
+	}
+	return err
+}
+
+// isRemote reports whether the Navigator browses another machine.
+func (n *Navigator) isRemote() bool {
+	_, ok := n.source.(remoteSource)
+	return ok
+}
+
+// checkWritable refuses changes to files while browsing a remote source.
+func (n *Navigator) checkWritable() error {
+	if n.isRemote() {
+		return errRemoteReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+// dirSize returns the total apparent size of the files beneath dir, at most
+// maxDepth levels down. Symlinks are counted as themselves and not followed,
+// and with oneFS set, like du -x, directories on other filesystems are left
+// out. Unreadable entries are skipped and reported in the returned error
+// alongside the partial total.
+func dirSize(source FileSource, dir string, oneFS bool, maxDepth int) (int64, error) {
+	var total int64
+	var errs []error
+	err := walkTree(source, dir, maxDepth, func(entry walkEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if entry.IsDir {
+			if oneFS && entry.MountPoint {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.entry.Info()
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, errors.Join(append(errs, err)...)
+}
+
+// MarkedTotalSize returns the combined size of the marked items, including
+// everything inside marked directories. Items that cannot be read are left
+// out of the total and reported together in the error.
+func (n *Navigator) MarkedTotalSize() (int64, error) {
+	var total int64
+	var errs []error
+	for _, item := range n.GetMarkedItems() {
+		if !item.IsDir {
+			total += n.withMetadata(item).Size
+			continue
+		}
+		size, err := dirSize(n.source, item.Path, n.config.OneFileSystem, n.config.MaxDepth)
+		total += size
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return total, errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarkedTotalSize(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "a.txt"), make([]byte, 100), 0644)
+	os.WriteFile(filepath.Join(tempDir, "b.txt"), make([]byte, 20), 0644)
+	os.WriteFile(filepath.Join(tempDir, "unmarked.txt"), make([]byte, 5000), 0644)
+	os.MkdirAll(filepath.Join(tempDir, "dir", "nested"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "dir", "c.bin"), make([]byte, 1000), 0644)
+	os.WriteFile(filepath.Join(tempDir, "dir", "nested", "d.bin"), make([]byte, 3), 0644)
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	for _, name := range []string{"a.txt", "b.txt", "dir"} {
+		selectByName(t, nav, name)
+		nav.ToggleMark()
+	}
+
+	total, err := nav.MarkedTotalSize()
+	if err != nil {
+		t.Fatalf("MarkedTotalSize failed: %v", err)
+	}
+	if total != 1123 {
+		t.Errorf("Expected 100+20+1000+3 = 1123 bytes, got %d", total)
+	}
+}
+
+func TestDirSizeReportsErrors(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 10), 0644)
+
+	total, err := dirSize(osSource{}, filepath.Join(dir, "missing"), false, defaultMaxDepth)
+	if err == nil || total != 0 {
+		t.Errorf("Expected an error and no size for a missing directory, got %d, %v", total, err)
+	}
+	if total, err := dirSize(osSource{}, dir, false, defaultMaxDepth); err != nil || total != 10 {
+		t.Errorf("Expected 10 bytes, got %d (err %v)", total, err)
+	}
+}
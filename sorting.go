@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortMode selects how directory entries are ordered.
+type SortMode int
+
+const (
+	SortByName SortMode = iota
+	SortByExtension
+	SortUnsorted
+)
+
+// sortModeNames maps sort modes to their config and display names, in cycle order.
+var sortModeNames = []string{
+	SortByName:      "name",
+	SortByExtension: "extension",
+	SortUnsorted:    "unsorted",
+}
+
+// String returns the name of the sort mode.
+func (m SortMode) String() string {
+	if int(m) < len(sortModeNames) {
+		return sortModeNames[m]
+	}
+	return "unknown"
+}
+
+// sortModeDescriptions describes each sort mode when it is switched to.
+var sortModeDescriptions = []string{
+	SortByName:      "name, directories first",
+	SortByExtension: "extension, grouped by file type",
+	SortUnsorted:    "unsorted, in directory order",
+}
+
+// Description returns a short explanation of the sort mode for status messages.
+func (m SortMode) Description() string {
+	if int(m) < len(sortModeDescriptions) {
+		return sortModeDescriptions[m]
+	}
+	return m.String()
+}
+
+// parseSortMode parses a sort mode name into dst.
+func parseSortMode(value string, dst *SortMode) error {
+	for mode, name := range sortModeNames {
+		if name == value {
+			*dst = SortMode(mode)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sort mode %q: expected one of %s", value, strings.Join(sortModeNames, ", "))
+}
+
+// sortItems orders the current directory's items with sortList, then lists
+// the contents of expanded directories beneath them.
+func (n *Navigator) sortItems() {
+	if len(n.expanded) == 0 {
+		n.sortList(n.items)
+		return
+	}
+	top := make([]FileItem, 0, len(n.items))
+	for _, item := range n.items {
+		if item.Depth == 0 {
+			top = append(top, item)
+		}
+	}
+	n.sortList(top)
+	n.items = n.expandTree(top, 0)
+}
+
+// sortList orders items according to the sort mode: the parent first, then
+// directories, then files. Items with equal keys fall back to name and then
+// path, so the order is the same on every rescan. The unsorted mode keeps the
+// order the items were read in, where the parent is already first.
+func (n *Navigator) sortList(items []FileItem) {
+	mode := n.config.SortMode
+	if mode == SortUnsorted {
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		itemI := items[i]
+		itemJ := items[j]
+
+		// The parent entry always comes first
+		if itemI.IsParent {
+			return true
+		}
+		if itemJ.IsParent {
+			return false
+		}
+
+		// Directories come before files
+		dirI, dirJ := n.groupsAsDir(itemI), n.groupsAsDir(itemJ)
+		if dirI != dirJ {
+			return dirI
+		}
+
+		// Group files by extension when requested
+		if mode == SortByExtension && !dirI {
+			extI, extJ := extensionOf(itemI.Name), extensionOf(itemJ.Name)
+			if extI != extJ {
+				return extI < extJ
+			}
+		}
+
+		// Ignoring case puts apple before Zebra; the exact name still breaks ties
+		if n.config.SortIgnoreCase {
+			lowerI, lowerJ := strings.ToLower(itemI.Name), strings.ToLower(itemJ.Name)
+			if lowerI != lowerJ {
+				return lowerI < lowerJ
+			}
+		}
+
+		// Alphabetical sort within category; names repeat in the flat view
+		if itemI.Name != itemJ.Name {
+			return itemI.Name < itemJ.Name
+		}
+		return itemI.Path < itemJ.Path
+	})
+}
+
+// ToggleSortIgnoreCase switches names between sorting case-sensitively, with
+// uppercase first, and ignoring case, and re-sorts the listing, keeping the
+// selection on the same item.
+func (n *Navigator) ToggleSortIgnoreCase() {
+	n.config.SortIgnoreCase = !n.config.SortIgnoreCase
+	if n.config.SortIgnoreCase {
+		n.SetStatusMessage("Sort: ignoring case")
+	} else {
+		n.SetStatusMessage("Sort: uppercase first")
+	}
+	n.resort()
+}
+
+// groupsAsDir reports whether item sorts with the directories. Symlinks to
+// directories join them when GroupSymlinks is set.
+func (n *Navigator) groupsAsDir(item FileItem) bool {
+	return item.IsDir || (n.config.GroupSymlinks && item.LinkDir)
+}
+
+// CycleSortMode switches to the next sort mode and re-sorts the listing,
+// keeping the selection on the same item. The new mode is announced in the
+// status bar.
+func (n *Navigator) CycleSortMode() {
+	n.config.SortMode = (n.config.SortMode + 1) % SortMode(len(sortModeNames))
+	n.SetStatusMessage("Sort: " + n.config.SortMode.Description())
+
+	// Only a rescan recovers the order entries are stored in
+	if n.config.SortMode == SortUnsorted {
+		if err := n.Refresh(); err != nil {
+			n.SetStatusMessage(fmt.Sprintf("Rescan failed: %v", err))
+		}
+		return
+	}
+	n.resort()
+}
+
+// resort sorts the listing again, keeping the selection on the same item.
+func (n *Navigator) resort() {
+	var selectedName string
+	if selectedItem := n.GetSelectedItem(); selectedItem != nil {
+		selectedName = selectedItem.Name
+	}
+	n.sortItems()
+	n.filterItems()
+	n.selectName(selectedName)
+}
+
+// extensionOf returns the lowercased extension of name without the dot. Dotfiles
+// such as ".bashrc" and names without a dot have no extension.
+func extensionOf(name string) string {
+	idx := strings.LastIndex(name, ".")
+	if idx <= 0 || idx == len(name)-1 {
+		return ""
+	}
+	return strings.ToLower(name[idx+1:])
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtensionOf(t *testing.T) {
+	tests := map[string]string{
+		"main.go":        "go",
+		"README.MD":      "md",
+		"archive.tar.gz": "gz",
+		".bashrc":        "",
+		".config.yaml":   "yaml",
+		"Makefile":       "",
+		"trailing.":      "",
+		"":               "",
+	}
+	for name, want := range tests {
+		if got := extensionOf(name); got != want {
+			t.Errorf("extensionOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSortByExtension(t *testing.T) {
+	tempDir := t.TempDir()
+	files := []string{"b.go", "a.md", "c.go", "Makefile", "a.go", ".bashrc", "notes.txt"}
+	for _, name := range files {
+		os.WriteFile(filepath.Join(tempDir, name), []byte("content"), 0644)
+	}
+	os.Mkdir(filepath.Join(tempDir, "zdir"), 0755)
+	os.Mkdir(filepath.Join(tempDir, "adir.go"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.SortMode = SortByExtension
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	// "../" stays first, directories keep name order, extensionless files lead
+	want := []string{"../", "adir.go", "zdir", ".bashrc", "Makefile", "a.go", "b.go", "c.go", "a.md", "notes.txt"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extension sort order = %v, want %v", got, want)
+	}
+
+	// Cycling through unsorted back to name sort keeps the selection on the same item
+	selectByName(t, nav, "a.md")
+	nav.CycleSortMode()
+	nav.CycleSortMode()
+	if nav.GetConfig().SortMode != SortByName {
+		t.Errorf("CycleSortMode expected name sort, got %v", nav.GetConfig().SortMode)
+	}
+	want = []string{"../", "adir.go", "zdir", ".bashrc", "Makefile", "a.go", "a.md", "b.go", "c.go", "notes.txt"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Name sort order = %v, want %v", got, want)
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "a.md" {
+		t.Errorf("CycleSortMode expected a.md to stay selected, got %v", selected)
+	}
+}
+
+func TestSortIgnoreCase(t *testing.T) {
+	// In memory, as apple and Apple cannot both exist on a case-insensitive
+	// filesystem
+	source := newMemSource(t, map[string]string{
+		"Zebra": "", "apple": "", "Apple": "", "banana": "", "Cherry.txt": "",
+		"docs/index.md": "", "Build/out": "",
+	})
+	nav, _ := NewNavigator(source.root)
+	nav.SetSource(source)
+	nav.ScanDirectory()
+	want := []string{"../", "Build", "docs", "Apple", "Cherry.txt", "Zebra", "apple", "banana"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Case-sensitive order = %v, want %v", got, want)
+	}
+
+	// Equal names apart from case keep uppercase first, so the order is stable
+	selectByName(t, nav, "banana")
+	nav.ToggleSortIgnoreCase()
+	want = []string{"../", "Build", "docs", "Apple", "apple", "banana", "Cherry.txt", "Zebra"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Case-insensitive order = %v, want %v", got, want)
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "banana" {
+		t.Errorf("Expected banana to stay selected, got %v", selected)
+	}
+}
+
+func TestSortItemsTiebreak(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	cfg := nav.GetConfig()
+	cfg.SortMode = SortByExtension
+	nav.SetConfig(cfg)
+
+	// Equal extensions fall back to the name, equal names to the path
+	items := []FileItem{
+		{Name: "c.go", Path: "/src/c.go"},
+		{Name: "main.go", Path: "/src/z/main.go"},
+		{Name: "a.go", Path: "/src/a.go"},
+		{Name: "main.go", Path: "/src/b/main.go"},
+		{Name: "main.go", Path: "/src/main.go"},
+		{Name: "b.go", Path: "/src/b.go"},
+	}
+	want := []string{"/src/a.go", "/src/b.go", "/src/c.go", "/src/b/main.go", "/src/main.go", "/src/z/main.go"}
+	for round := 0; round < 3; round++ {
+		nav.items = append([]FileItem(nil), items...)
+		nav.sortItems()
+		var got []string
+		for _, item := range nav.items {
+			got = append(got, item.Path)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Sort order = %v, want %v", got, want)
+		}
+		// Start the next round from a different order
+		items = append(items[1:], items[0])
+	}
+}
+
+func TestGroupSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	os.Mkdir(filepath.Join(tempDir, "real"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("content"), 0644)
+	if err := os.Symlink(filepath.Join(tempDir, "real"), filepath.Join(tempDir, "dirlink")); err != nil {
+		t.Skipf("Cannot create symlinks: %v", err)
+	}
+	os.Symlink(filepath.Join(tempDir, "a.txt"), filepath.Join(tempDir, "filelink"))
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+
+	// By default symlinks sort with the files, whatever they point at
+	want := []string{"../", "real", "a.txt", "dirlink", "filelink"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Default order = %v, want %v", got, want)
+	}
+
+	cfg := nav.GetConfig()
+	cfg.GroupSymlinks = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	want = []string{"../", "dirlink", "real", "a.txt", "filelink"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Grouped order = %v, want %v", got, want)
+	}
+}
+
+func TestCycleSortModeAnnounces(t *testing.T) {
+	nav, _ := NewNavigator(t.TempDir())
+	nav.ScanDirectory()
+
+	nav.CycleSortMode()
+	if mode := nav.GetConfig().SortMode; mode != SortByExtension {
+		t.Errorf("Expected extension sort after cycling, got %v", mode)
+	}
+	if msg := nav.GetStatusMessage(); msg != "Sort: extension, grouped by file type" {
+		t.Errorf("Expected the extension sort to be announced, got %q", msg)
+	}
+	if bar := buildStatusBar(nav, 1); bar != "Sort: extension, grouped by file type" {
+		t.Errorf("Expected the status bar to show the announcement, got %q", bar)
+	}
+	// Once the message is gone the item counts still show the mode
+	nav.SetStatusMessage("")
+	if bar := buildStatusBar(nav, 1); !strings.HasPrefix(bar, "[1 items, by extension]") {
+		t.Errorf("Expected the status bar to show the sort mode, got %q", bar)
+	}
+
+	nav.CycleSortMode()
+	nav.CycleSortMode()
+	if msg := nav.GetStatusMessage(); msg != "Sort: name, directories first" {
+		t.Errorf("Expected the name sort to be announced, got %q", msg)
+	}
+}
+
+// orderedSource lists every directory with the entries in a fixed order.
+type orderedSource struct {
+	memSource
+	order []string
+}
+
+func (s orderedSource) ReadDirRaw(path string) ([]FileItem, error) {
+	var items []FileItem
+	for _, name := range s.order {
+		items = append(items, FileItem{Name: name, Path: filepath.Join(path, name)})
+	}
+	return items, nil
+}
+
+func TestUnsortedKeepsSourceOrder(t *testing.T) {
+	files := map[string]string{"b.txt": "", "c.txt": "", "a.txt": ""}
+	source := orderedSource{memSource: newMemSource(t, files), order: []string{"c.txt", "a.txt", "b.txt"}}
+
+	nav, _ := NewNavigator(source.root)
+	nav.SetSource(source)
+	cfg := nav.GetConfig()
+	cfg.SortMode = SortUnsorted
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	want := []string{"../", "c.txt", "a.txt", "b.txt"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unsorted order = %v, want %v", got, want)
+	}
+
+	// Other modes use the sorted listing
+	nav.CycleSortMode()
+	want = []string{"../", "a.txt", "b.txt", "c.txt"}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Name order after cycling = %v, want %v", got, want)
+	}
+}
+
+func TestReadDirRawMatchesFile(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"m", "z", "a", "q"} {
+		os.WriteFile(filepath.Join(tempDir, name), nil, 0644)
+	}
+
+	f, _ := os.Open(tempDir)
+	entries, _ := f.ReadDir(-1)
+	f.Close()
+	var want []string
+	for _, entry := range entries {
+		want = append(want, entry.Name())
+	}
+
+	items, err := osSource{}.ReadDirRaw(tempDir)
+	if err != nil {
+		t.Fatalf("ReadDirRaw failed: %v", err)
+	}
+	if got := itemNames(items); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDirRaw order = %v, want the stored order %v", got, want)
+	}
+}
+
+func TestParseSortMode(t *testing.T) {
+	var mode SortMode
+	if err := parseSortMode("extension", &mode); err != nil || mode != SortByExtension {
+		t.Errorf("parseSortMode(extension) = %v, %v", mode, err)
+	}
+	if err := parseSortMode("bogus", &mode); err == nil {
+		t.Error("parseSortMode accepted an unknown mode")
+	}
+}
+
+func itemNames(items []FileItem) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	return names
+}
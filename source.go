@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileSource provides directory listings and file contents to the Navigator,
+// so they can come from somewhere other than the local filesystem. Paths use
+// the platform's separator, as with the os package.
+type FileSource interface {
+	// ReadDir returns the entries of the directory at path sorted by name,
+	// without "../".
+	ReadDir(path string) ([]FileItem, error)
+	// Stat describes the file at path, following symlinks.
+	Stat(path string) (fs.FileInfo, error)
+	// Open opens the file at path for reading.
+	Open(path string) (io.ReadCloser, error)
+}
+
+// osSource reads from the local filesystem. With mounts, listings flag the
+// mount points among their directories, which takes a stat of each.
+type osSource struct {
+	mounts bool
+}
+
+// rawDirReader is implemented by sources that can list a directory in the
+// order it is stored, as ls -U does, for the unsorted sort mode.
+type rawDirReader interface {
+	ReadDirRaw(path string) ([]FileItem, error)
+}
+
+// ReadDir lists path with os.ReadDir, resolving symlinks to record whether
+// they point at a directory.
+func (s osSource) ReadDir(path string) ([]FileItem, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.items(path, entries), nil
+}
+
+// ReadDirRaw lists path in the order the filesystem returns its entries, using
+// (*os.File).ReadDir, which unlike os.ReadDir does not sort them.
+func (s osSource) ReadDirRaw(path string) ([]FileItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := f.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	return s.items(path, entries), nil
+}
+
+// items converts the entries of dir into items, flagging mount points with
+// s.mounts.
+func (s osSource) items(dir string, entries []fs.DirEntry) []FileItem {
+	items := itemsFromEntries(dir, entries)
+	if s.mounts {
+		items = markMountPoints(dir, items)
+	}
+	return resolveLinkDirs(items)
+}
+
+// resolveLinkDirs records which symlinks among items point at a directory.
+func resolveLinkDirs(items []FileItem) []FileItem {
+	for i := range items {
+		if items[i].Type == TypeSymlink {
+			info, err := os.Stat(items[i].Path)
+			items[i].LinkDir = err == nil && info.IsDir()
+		}
+	}
+	return items
+}
+
+// linkReader is implemented by sources that can read the target of a symlink,
+// for the details popup.
+type linkReader interface {
+	Readlink(path string) (string, error)
+}
+
+// Readlink calls os.Readlink.
+func (osSource) Readlink(path string) (string, error) {
+	return os.Readlink(path)
+}
+
+// Stat calls os.Stat.
+func (osSource) Stat(path string) (fs.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// Open calls os.Open.
+func (osSource) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// itemsFromEntries converts the entries of dir into items. Metadata is not
+// read here but by withMetadata, only when something shows it.
+func itemsFromEntries(dir string, entries []fs.DirEntry) []FileItem {
+	items := make([]FileItem, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		items = append(items, FileItem{
+			Name:     name,
+			Path:     filepath.Join(dir, name),
+			IsDir:    entry.IsDir(),
+			IsHidden: len(name) > 0 && name[0] == '.',
+			Type:     fileTypeOf(entry.Type()),
+			DiskSize: -1,
+			entry:    entry,
+		})
+	}
+	return items
+}
+
+// SetSource replaces where directory listings are read from.
+func (n *Navigator) SetSource(source FileSource) {
+	n.source = source
+}
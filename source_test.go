@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// memSource serves files from memory, mapping paths below root onto fsys.
+type memSource struct {
+	root string
+	fsys fstest.MapFS
+}
+
+func (s memSource) name(path string) (string, error) {
+	rel, err := filepath.Rel(s.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", os.ErrNotExist
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (s memSource) ReadDir(path string) ([]FileItem, error) {
+	name, err := s.name(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return itemsFromEntries(path, entries), nil
+}
+
+func (s memSource) Stat(path string) (fs.FileInfo, error) {
+	name, err := s.name(path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(s.fsys, name)
+}
+
+func (s memSource) Open(path string) (io.ReadCloser, error) {
+	name, err := s.name(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.fsys.Open(name)
+}
+
+// newMemSource returns an in-memory source rooted at an absolute path that
+// does not exist on disk.
+func newMemSource(t *testing.T, files map[string]string) memSource {
+	root, err := filepath.Abs(filepath.Join(string(filepath.Separator), "nav-mem-source"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content), Mode: 0644}
+	}
+	return memSource{root: root, fsys: fsys}
+}
+
+func TestScanDirectoryUsesSource(t *testing.T) {
+	source := newMemSource(t, map[string]string{
+		"readme.md":     "# nav",
+		"docs/guide.md": "guide",
+		"docs/faq.md":   "faq",
+		"src/main.go":   "package main",
+	})
+	nav, _ := NewNavigator(source.root)
+	nav.SetSource(source)
+
+	if err := nav.ScanDirectory(); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if got, want := itemNames(nav.GetItems()), []string{"../", "docs", "src", "readme.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected items %v, got %v", want, got)
+	}
+
+	selectByName(t, nav, "docs")
+	if err := nav.OpenSelected(); err != nil {
+		t.Fatalf("OpenSelected failed: %v", err)
+	}
+	if got, want := itemNames(nav.GetItems()), []string{"../", "faq.md", "guide.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected items %v after navigating, got %v", want, got)
+	}
+
+	// Siblings, previews and go-to all read through the source
+	if err := nav.NextSibling(); err != nil || nav.GetCurrentPath() != filepath.Join(source.root, "src") {
+		t.Errorf("Expected NextSibling to reach src, got %q (err %v)", nav.GetCurrentPath(), err)
+	}
+	selectByName(t, nav, "main.go")
+	if lines, err := nav.PreviewSelected(); err != nil || !reflect.DeepEqual(lines, []string{"package main"}) {
+		t.Errorf("Expected preview of main.go, got %v (err %v)", lines, err)
+	}
+	if err := nav.GoToPath(filepath.Join(source.root, "docs", "faq.md")); err != nil {
+		t.Fatalf("GoToPath failed: %v", err)
+	}
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "faq.md" {
+		t.Errorf("Expected faq.md revealed, got %v", selected)
+	}
+
+	// The flat view walks the source too
+	nav.changeDirectory(source.root)
+	nav.ToggleFlatView()
+	want := []string{"../", filepath.Join("docs", "faq.md"), filepath.Join("docs", "guide.md"), "readme.md", filepath.Join("src", "main.go")}
+	if got := itemNames(nav.GetItems()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected flat items %v, got %v", want, got)
+	}
+
+	if err := nav.changeDirectory(filepath.Join(source.root, "missing")); err == nil {
+		t.Error("Expected an error for a directory missing from the source")
+	}
+}
+
+func TestOSSourceReadDir(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	items, err := osSource{}.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	for _, item := range items {
+		if item.Path != filepath.Join(tempDir, item.Name) {
+			t.Errorf("Expected %s under %s, got %s", item.Name, tempDir, item.Path)
+		}
+		if item.IsHidden != strings.HasPrefix(item.Name, ".") {
+			t.Errorf("Hidden flag wrong for %s", item.Name)
+		}
+	}
+	assertContainsAll(t, items, []string{"dir1", "file1.txt", ".hidden_file"})
+}
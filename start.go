@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultStartFile returns the file holding the pinned start directory, kept
+// next to the config file.
+func defaultStartFile() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "default_start"), nil
+}
+
+// SetDefaultStart pins the current directory as the start directory for
+// launches without a path argument.
+func (n *Navigator) SetDefaultStart() error {
+	if n.isRemote() {
+		return errors.New("remote directories cannot be pinned as the start")
+	}
+	path, err := defaultStartFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(n.currentPath+"\n"), 0644)
+}
+
+// loadDefaultStart returns the pinned start directory, or "" when none is
+// pinned or it no longer exists.
+func loadDefaultStart() string {
+	path, err := defaultStartFile()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Clean(strings.TrimSpace(string(data)))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// resolveStartPath picks the directory to start in: the path given on the
+// command line, then the pinned default, then the working directory.
+func resolveStartPath(arg string) string {
+	if arg != "" {
+		return arg
+	}
+	if pinned := loadDefaultStart(); pinned != "" {
+		return pinned
+	}
+	return "."
+}
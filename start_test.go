@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetDefaultStart(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("NAV_CONFIG", filepath.Join(configDir, "nav", "config"))
+	start := t.TempDir()
+
+	nav, _ := NewNavigator(start)
+	if err := nav.SetDefaultStart(); err != nil {
+		t.Fatalf("SetDefaultStart failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(configDir, "nav", "default_start"))
+	if err != nil {
+		t.Fatalf("Expected the pinned start to be saved: %v", err)
+	}
+	if string(data) != start+"\n" {
+		t.Errorf("Expected %q saved, got %q", start+"\n", data)
+	}
+	if got := loadDefaultStart(); got != start {
+		t.Errorf("Expected loadDefaultStart to return %s, got %q", start, got)
+	}
+}
+
+func TestResolveStartPath(t *testing.T) {
+	t.Setenv("NAV_CONFIG", filepath.Join(t.TempDir(), "config"))
+	if got := resolveStartPath(""); got != "." {
+		t.Errorf("Expected the working directory without a pin, got %q", got)
+	}
+
+	pinned := t.TempDir()
+	nav, _ := NewNavigator(pinned)
+	nav.SetDefaultStart()
+	if got := resolveStartPath(""); got != pinned {
+		t.Errorf("Expected the pinned start %s, got %q", pinned, got)
+	}
+	// A path on the command line wins over the pin
+	if got := resolveStartPath("/tmp"); got != "/tmp" {
+		t.Errorf("Expected the argument to take precedence, got %q", got)
+	}
+
+	// A pinned directory that was removed is ignored
+	os.Remove(pinned)
+	if got := resolveStartPath(""); got != "." {
+		t.Errorf("Expected a missing pin to fall back to the working directory, got %q", got)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StashSelected copies the selected item into the stash_dir directory,
+// creating it first, for collecting files over a session. A name already in
+// the stash is handled as on_collision says, with ask refusing.
+func (n *Navigator) StashSelected() error {
+	return n.stashSelected(nil)
+}
+
+// StashCollision returns the selected item's name when the stash already
+// holds an entry by that name, or "" when it does not.
+func (n *Navigator) StashCollision() string {
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil || selectedItem.IsParent || n.config.StashDir == "" {
+		return ""
+	}
+	name := filepath.Base(selectedItem.Path)
+	if _, err := os.Lstat(filepath.Join(n.config.StashDir, name)); err != nil {
+		return ""
+	}
+	return name
+}
+
+// stashSelected stashes the selected item, settling a collision as choices
+// says, or else as on_collision does.
+func (n *Navigator) stashSelected(choices map[string]CollisionPolicy) error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+	stashDir := n.config.StashDir
+	if stashDir == "" {
+		return errors.New("no stash directory set (stash_dir in the config)")
+	}
+	selectedItem := n.requireSelection()
+	if selectedItem == nil || selectedItem.IsParent {
+		return nil
+	}
+	if err := os.MkdirAll(stashDir, 0755); err != nil {
+		return err
+	}
+
+	name := filepath.Base(selectedItem.Path)
+	dst := filepath.Join(stashDir, name)
+	if dst == selectedItem.Path {
+		return fmt.Errorf("%s is already in the stash", name)
+	}
+	copyTo := func(dst string) error {
+		return copyPath(selectedItem.Path, dst, n.config.MaxDepth, n.config.PreserveAttributes, n.config.OneFileSystem)
+	}
+	stash := func() error { return copyTo(dst) }
+	if _, err := os.Lstat(dst); err == nil {
+		switch n.collisionPolicy(name, choices) {
+		case CollisionSkip:
+			n.SetStatusMessage(name + " is already in the stash")
+			return nil
+		case CollisionRename:
+			dst = freeName(stashDir, name)
+		case CollisionOverwrite:
+			stash = func() error {
+				return replaceEntry(selectedItem.Path, dst, func() error { return copyTo(dst) })
+			}
+		default:
+			return fmt.Errorf("%s already exists in %s", name, stashDir)
+		}
+	}
+
+	if err := stash(); err != nil {
+		return err
+	}
+	n.logOperation("copy", "stash", selectedItem.Path, dst)
+	n.SetStatusMessage(fmt.Sprintf("Stashed %s in %s", filepath.Base(dst), stashDir))
+	if filepath.Dir(dst) == n.currentPath {
+		return n.Refresh()
+	}
+	return nil
+}
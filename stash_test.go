@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStashSelected(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+	stashDir := filepath.Join(t.TempDir(), "stash", "inbox")
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "file1.txt")
+	if err := nav.StashSelected(); err == nil {
+		t.Error("Expected an error without stash_dir")
+	}
+
+	cfg := nav.GetConfig()
+	cfg.StashDir = stashDir
+	nav.SetConfig(cfg)
+	os.WriteFile(filepath.Join(tempDir, "file1.txt"), []byte("first"), 0644)
+	if err := nav.StashSelected(); err != nil {
+		t.Fatalf("StashSelected failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(stashDir, "file1.txt")); string(data) != "first" {
+		t.Errorf("Expected file1.txt in the stash, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "file1.txt")); err != nil {
+		t.Errorf("Expected the original to stay: %v", err)
+	}
+	if nav.GetStatusMessage() == "" {
+		t.Error("Expected a status confirmation")
+	}
+	if nav.StashCollision() != "file1.txt" {
+		t.Errorf("Expected file1.txt to collide now, got %q", nav.StashCollision())
+	}
+
+	// Directories are copied whole
+	selectByName(t, nav, "dir1")
+	os.WriteFile(filepath.Join(tempDir, "dir1", "inner.txt"), []byte("inner"), 0644)
+	if err := nav.StashSelected(); err != nil {
+		t.Fatalf("StashSelected failed for a directory: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(stashDir, "dir1", "inner.txt")); string(data) != "inner" {
+		t.Errorf("Expected dir1/inner.txt in the stash, got %q", data)
+	}
+}
+
+// The policies themselves are covered by TestMoveCollisionPolicies; this
+// only checks that stashing goes through them.
+func TestStashCollision(t *testing.T) {
+	dir := t.TempDir()
+	stashDir := filepath.Join(dir, "stash")
+	os.Mkdir(stashDir, 0755)
+	os.WriteFile(filepath.Join(stashDir, "notes.txt"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("new"), 0644)
+
+	nav, _ := NewNavigator(dir)
+	cfg := nav.GetConfig()
+	cfg.StashDir, cfg.OnCollision = stashDir, CollisionRename
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+	selectByName(t, nav, "notes.txt")
+
+	if err := nav.StashSelected(); err != nil {
+		t.Fatalf("StashSelected failed: %v", err)
+	}
+	for name, want := range map[string]string{"notes.txt": "old", "notes (1).txt": "new"} {
+		if data, _ := os.ReadFile(filepath.Join(stashDir, name)); string(data) != want {
+			t.Errorf("Expected stash/%s to hold %q, got %q", name, want, data)
+		}
+	}
+}
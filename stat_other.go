@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import "io/fs"
+
+// diskSize is unsupported here, so the size column shows apparent sizes.
+func diskSize(info fs.FileInfo) (int64, bool) {
+	return 0, false
+}
+
+// fileIDs is unsupported here, so details leave out inode and device numbers.
+func fileIDs(info fs.FileInfo) (inode, device, links uint64, ok bool) {
+	return 0, 0, 0, false
+}
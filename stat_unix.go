@@ -0,0 +1,28 @@
+//go:build unix
+
+package main
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// diskSize returns the space allocated to a file, from its block count. stat
+// reports blocks in 512-byte units regardless of the filesystem block size.
+func diskSize(info fs.FileInfo) (int64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int64(stat.Blocks) * 512, true
+}
+
+// fileIDs returns the inode, device and hard link count of a file. Hardlinked
+// files share an inode on the same device.
+func fileIDs(info fs.FileInfo) (inode, device, links uint64, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return uint64(stat.Ino), uint64(stat.Dev), uint64(stat.Nlink), true
+}
@@ -0,0 +1,149 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiskSizeSparseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sparse")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Seeking past the end leaves a hole that most filesystems do not allocate
+	file.Truncate(64 << 20)
+	file.Close()
+
+	info, _ := os.Stat(path)
+	size, ok := diskSize(info)
+	if !ok {
+		t.Fatal("Expected diskSize to be supported on Unix")
+	}
+	if size >= info.Size() {
+		t.Skipf("filesystem allocated the hole (%d of %d bytes)", size, info.Size())
+	}
+}
+
+func TestDetailsShowInode(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.txt")
+	os.WriteFile(original, []byte("content"), 0644)
+	if err := os.Link(original, filepath.Join(dir, "link.txt")); err != nil {
+		t.Skipf("hard links unsupported: %v", err)
+	}
+
+	nav, _ := NewNavigator(dir)
+	nav.ScanDirectory()
+	inodes := map[string]uint64{}
+	for _, name := range []string{"original.txt", "link.txt"} {
+		selectByName(t, nav, name)
+		details, err := nav.SelectedDetails()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !details.HasIDs || details.Inode == 0 {
+			t.Fatalf("Expected an inode for %s, got %+v", name, details)
+		}
+		if details.Links != 2 {
+			t.Errorf("Expected %s to have 2 links, got %d", name, details.Links)
+		}
+		inodes[name] = details.Inode
+
+		lines := strings.Join(detailsLines(details, true), "\n")
+		if !strings.Contains(lines, fmt.Sprintf("Inode:    %d", details.Inode)) {
+			t.Errorf("Expected the inode in the details, got:\n%s", lines)
+		}
+		if strings.Contains(strings.Join(detailsLines(details, false), "\n"), "Inode:") {
+			t.Error("Expected the inode to be hidden when the toggle is off")
+		}
+	}
+	if inodes["original.txt"] != inodes["link.txt"] {
+		t.Errorf("Expected hardlinked files to share an inode, got %v", inodes)
+	}
+}
+
+func TestDetailsCached(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	os.WriteFile(path, []byte("content"), 0644)
+
+	nav, _ := NewNavigator(dir)
+	nav.ScanDirectory()
+	selectByName(t, nav, "file.txt")
+	first, _ := nav.SelectedDetails()
+
+	// The cached details survive until the listing is rescanned
+	os.WriteFile(path, []byte("longer content"), 0644)
+	if second, _ := nav.SelectedDetails(); second != first {
+		t.Error("Expected repeated calls to reuse the cached details")
+	}
+	nav.Refresh()
+	selectByName(t, nav, "file.txt")
+	if third, _ := nav.SelectedDetails(); third.Size != int64(len("longer content")) {
+		t.Errorf("Expected a rescan to refresh the details, got size %d", third.Size)
+	}
+}
+
+func TestOnOtherDevice(t *testing.T) {
+	if deviceBoundary(1, true, 1, true) {
+		t.Error("Expected the same device not to be a boundary")
+	}
+	if !deviceBoundary(2, true, 1, true) {
+		t.Error("Expected a different device to be a boundary")
+	}
+	if deviceBoundary(2, false, 1, true) || deviceBoundary(2, true, 1, false) {
+		t.Error("Expected a missing device number not to be a boundary")
+	}
+
+	dir := t.TempDir()
+	info, _ := os.Stat(dir)
+	parentInfo, _ := os.Stat(filepath.Dir(dir))
+	if onOtherDevice(info, parentInfo) {
+		t.Error("Expected a temp dir to share its parent's device")
+	}
+}
+
+func TestIsMountPoint(t *testing.T) {
+	if mount, err := isMountPoint("/"); err != nil || !mount {
+		t.Errorf("Expected / to be a mount point, got %v (err %v)", mount, err)
+	}
+
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	if mount, err := isMountPoint(filepath.Join(dir, "sub")); err != nil || mount {
+		t.Errorf("Expected a plain subdirectory not to be a mount point, got %v (err %v)", mount, err)
+	}
+	if _, err := isMountPoint(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
+
+func TestOSSourceMarksMountsOnRequest(t *testing.T) {
+	for _, mounts := range []bool{false, true} {
+		items, err := osSource{mounts: mounts}.ReadDir("/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			want := false
+			if mounts && item.IsDir {
+				want, _ = isMountPoint(item.Path)
+			}
+			if item.MountPoint != want {
+				t.Errorf("With mounts %v, expected %s flagged %v, got %v", mounts, item.Name, want, item.MountPoint)
+			}
+		}
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "a", "b"), 0755)
+	if mount, err := findMountPoint(dir, defaultMaxDepth); err != nil || mount != "" {
+		t.Errorf("Expected no mount point in a temp dir, got %q (err %v)", mount, err)
+	}
+}
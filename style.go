@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Selection style presets for the selection_style setting.
+const (
+	SelectionDefault      = "default"
+	SelectionHighContrast = "high-contrast"
+	SelectionReverse      = "reverse"
+)
+
+// selectionStyle returns the style of the selected row: the preset from the
+// selection_style setting, with selection_fg and selection_bg applied on top.
+// Color values were checked when the config was parsed.
+func selectionStyle(cfg Config, defStyle tcell.Style) tcell.Style {
+	var style tcell.Style
+	switch cfg.SelectionStyle {
+	case SelectionHighContrast:
+		style = defStyle.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack).Bold(true)
+	case SelectionReverse:
+		style = defStyle.Reverse(true)
+	default:
+		style = defStyle.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorBlack)
+	}
+	if cfg.SelectionFG != "" {
+		style = style.Foreground(tcell.GetColor(cfg.SelectionFG))
+	}
+	if cfg.SelectionBG != "" {
+		style = style.Background(tcell.GetColor(cfg.SelectionBG))
+	}
+	return style
+}
+
+// parseSelectionStyle validates a selection style preset into dst.
+func parseSelectionStyle(value string, dst *string) error {
+	switch value {
+	case SelectionDefault, SelectionHighContrast, SelectionReverse:
+		*dst = value
+		return nil
+	}
+	return fmt.Errorf("invalid selection style %q: expected %s, %s or %s", value, SelectionDefault, SelectionHighContrast, SelectionReverse)
+}
+
+// parseColor validates a color name such as "yellow" or a hex value such as
+// "#ffcc00" into dst.
+func parseColor(value string, dst *string) error {
+	value = strings.ToLower(strings.Trim(value, `"`))
+	if tcell.GetColor(value) == tcell.ColorDefault {
+		return fmt.Errorf("invalid color %q", value)
+	}
+	*dst = value
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestSelectionStyle(t *testing.T) {
+	defStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
+	tests := []struct {
+		config string
+		fg, bg tcell.Color
+		attrs  tcell.AttrMask
+	}{
+		{"", tcell.ColorBlack, tcell.ColorDarkCyan, 0},
+		{"selection_style = high-contrast", tcell.ColorBlack, tcell.ColorYellow, tcell.AttrBold},
+		{"selection_style = reverse", tcell.ColorWhite, tcell.ColorBlack, tcell.AttrReverse},
+		{"selection_fg = red", tcell.ColorRed, tcell.ColorDarkCyan, 0},
+		{"selection_style = high-contrast\nselection_bg = \"#ffcc00\"", tcell.ColorBlack, tcell.NewHexColor(0xffcc00), tcell.AttrBold},
+	}
+	for _, tt := range tests {
+		cfg, err := parseConfig(strings.NewReader(tt.config))
+		if err != nil {
+			t.Fatalf("parseConfig(%q) failed: %v", tt.config, err)
+		}
+		fg, bg, attrs := selectionStyle(cfg, defStyle).Decompose()
+		if fg != tt.fg || bg != tt.bg || attrs != tt.attrs {
+			t.Errorf("Config %q expected fg=%v bg=%v attrs=%v, got fg=%v bg=%v attrs=%v", tt.config, tt.fg, tt.bg, tt.attrs, fg, bg, attrs)
+		}
+	}
+}
+
+func TestSelectionStyleInvalid(t *testing.T) {
+	for _, config := range []string{"selection_style = loud", "selection_fg = notacolor", "selection_bg = #12"} {
+		if _, err := parseConfig(strings.NewReader(config)); err == nil {
+			t.Errorf("Expected %q to be rejected", config)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// treeMaxDepth and treeMaxLines bound the trees copied with CopyTree and
+	// ExportMarkdownTree
+	treeMaxDepth = 3
+	treeMaxLines = 500
+)
+
+// RenderTree draws root and the entries below it, down to maxDepth levels, in
+// the style of tree(1), with directories before files at each level. At most
+// maxLines lines are produced, counting the root's own line, followed by a
+// note when the tree was cut.
+// Symlinked directories are not followed, and with oneFS set neither are
+// mount points.
+func RenderTree(source FileSource, root string, maxDepth, maxLines int, oneFS bool, glyphs Glyphs) []string {
+	lines := []string{filepath.Base(root) + "/"}
+	entries, truncated := treeEntries(source, root, maxDepth, maxLines-1, oneFS)
+
+	// indents[d] prefixes the entries d levels below root's own
+	indents := []string{""}
+	for _, entry := range entries {
+		indent := indents[entry.Depth-1]
+		lines = append(lines, indent+treePrefix(entry.Last, glyphs)+treeName(entry))
+
+		childIndent := indent + glyphs.Trunk
+		if entry.Last {
+			childIndent = indent + strings.Repeat(" ", len([]rune(glyphs.Trunk)))
+		}
+		indents = append(indents[:entry.Depth], childIndent)
+	}
+
+	if truncated {
+		lines = append(lines, fmt.Sprintf("%s (truncated at %d lines)", glyphs.Ellipsis, maxLines))
+	}
+	return lines
+}
+
+// RenderMarkdownTree lists root and the entries below it as a Markdown nested
+// list, bounded and ordered like RenderTree. Names are written as code spans
+// so characters such as _ and * show as they are.
+func RenderMarkdownTree(source FileSource, root string, maxDepth, maxLines int, oneFS bool) []string {
+	lines := []string{"- " + markdownCode(filepath.Base(root)+"/")}
+	entries, truncated := treeEntries(source, root, maxDepth, maxLines-1, oneFS)
+	for _, entry := range entries {
+		lines = append(lines, strings.Repeat("  ", entry.Depth)+"- "+markdownCode(treeName(entry)))
+	}
+	if truncated {
+		lines = append(lines, fmt.Sprintf("  - … (truncated at %d lines)", maxLines))
+	}
+	return lines
+}
+
+// treeEntries walks up to limit entries below root for the renderers above,
+// reporting whether more were left out.
+func treeEntries(source FileSource, root string, maxDepth, limit int, oneFS bool) (entries []walkEntry, truncated bool) {
+	walkTree(source, root, maxDepth, func(entry walkEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(entries) >= limit {
+			truncated = true
+			return errStopWalk
+		}
+		entries = append(entries, entry)
+
+		if !entry.IsDir {
+			return nil
+		}
+		if entry.Depth >= maxDepth || (oneFS && entry.MountPoint) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return entries, truncated
+}
+
+// treeName returns the name an entry is listed under in a tree, with its
+// type indicator and a slash after directories.
+func treeName(entry walkEntry) string {
+	name := entry.Name + entry.Type.Indicator()
+	if entry.IsDir {
+		name += "/"
+	}
+	return name
+}
+
+// markdownCode writes s as a Markdown code span, fenced with more backticks
+// than any run within it.
+func markdownCode(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// CopyTree copies a bounded tree of the current directory to the clipboard as
+// text and returns how many lines it holds.
+func (n *Navigator) CopyTree() (int, error) {
+	lines := RenderTree(n.source, n.currentPath, treeMaxDepth, treeMaxLines, n.config.OneFileSystem, glyphsFor(n.config.ASCII))
+	if err := n.clipboard(strings.Join(lines, "\n") + "\n"); err != nil {
+		return 0, err
+	}
+	return len(lines), nil
+}
+
+// ExportMarkdownTree writes a bounded tree of the current directory as a
+// Markdown nested list to the clipboard when dest is empty, or else to the
+// new file dest, relative to the current directory. It returns how many lines
+// were written. An existing file is never replaced, and no file is written
+// while browsing a remote source.
+func (n *Navigator) ExportMarkdownTree(dest string) (int, error) {
+	if dest != "" {
+		if err := n.checkWritable(); err != nil {
+			return 0, err
+		}
+	}
+	lines := RenderMarkdownTree(n.source, n.currentPath, treeMaxDepth, treeMaxLines, n.config.OneFileSystem)
+	text := strings.Join(lines, "\n") + "\n"
+	if dest == "" {
+		return len(lines), n.clipboard(text)
+	}
+
+	path := normalizePath(dest, n.currentPath)
+	if err := writeFileAtomic(path, []byte(text), 0644); err != nil {
+		return 0, err
+	}
+	n.logOperation("create", "markdown tree", path)
+	return len(lines), n.Refresh()
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderTree(t *testing.T) {
+	source := newMemSource(t, map[string]string{
+		"README.md":             "",
+		"src/main.go":           "",
+		"src/util/strings.go":   "",
+		"src/util/deep/more.go": "",
+		"docs/guide.md":         "",
+	})
+
+	got := RenderTree(source, source.root, 3, 100, false, unicodeGlyphs)
+	want := []string{
+		"nav-mem-source/",
+		"├── docs/",
+		"│   └── guide.md",
+		"├── src/",
+		"│   ├── util/",
+		"│   │   ├── deep/",
+		"│   │   └── strings.go",
+		"│   └── main.go",
+		"└── README.md",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderTree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The line limit cuts the tree short with a note
+	got = RenderTree(source, source.root, 3, 3, false, asciiGlyphs)
+	want = []string{"nav-mem-source/", "|-- docs/", "|   `-- guide.md", "... (truncated at 3 lines)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Truncated RenderTree = %q, want %q", got, want)
+	}
+}
+
+// mountSource reports the directories named in mounts as mount points.
+type mountSource struct {
+	memSource
+	mounts map[string]bool
+}
+
+func (s mountSource) ReadDir(path string) ([]FileItem, error) {
+	items, err := s.memSource.ReadDir(path)
+	for i := range items {
+		items[i].MountPoint = s.mounts[items[i].Name]
+	}
+	return items, err
+}
+
+func TestRenderTreeOneFileSystem(t *testing.T) {
+	source := mountSource{
+		memSource: newMemSource(t, map[string]string{"mnt/disk.img": "", "src/main.go": ""}),
+		mounts:    map[string]bool{"mnt": true},
+	}
+
+	got := RenderTree(source, source.root, 3, 100, true, unicodeGlyphs)
+	want := []string{"nav-mem-source/", "├── mnt/", "└── src/", "    └── main.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderTree on one filesystem = %q, want %q", got, want)
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	source := newMemSource(t, map[string]string{"a/b.txt": "", "c.txt": ""})
+	nav, _ := NewNavigator(source.root)
+	nav.SetSource(source)
+
+	var copied string
+	nav.clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	lines, err := nav.CopyTree()
+	if err != nil {
+		t.Fatalf("CopyTree failed: %v", err)
+	}
+
+	want := strings.Join(RenderTree(source, source.root, treeMaxDepth, treeMaxLines, false, unicodeGlyphs), "\n") + "\n"
+	if copied != want {
+		t.Errorf("Expected the rendered tree to be copied, got %q, want %q", copied, want)
+	}
+	if lines != 4 {
+		t.Errorf("Expected 4 lines, got %d", lines)
+	}
+}
+
+func TestRenderMarkdownTree(t *testing.T) {
+	source := newMemSource(t, map[string]string{
+		"README.md":           "",
+		"src/main.go":         "",
+		"src/my_util/deep.go": "",
+		"docs/guide.md":       "",
+	})
+
+	got := RenderMarkdownTree(source, source.root, 3, 100, false)
+	want := []string{
+		"- `nav-mem-source/`",
+		"  - `docs/`",
+		"    - `guide.md`",
+		"  - `src/`",
+		"    - `my_util/`",
+		"      - `deep.go`",
+		"    - `main.go`",
+		"  - `README.md`",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderMarkdownTree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Depth and line bounds apply as for the text tree
+	got = RenderMarkdownTree(source, source.root, 1, 3, false)
+	want = []string{"- `nav-mem-source/`", "  - `docs/`", "  - `src/`", "  - … (truncated at 3 lines)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Bounded RenderMarkdownTree = %q, want %q", got, want)
+	}
+
+	if got := markdownCode("a`b"); got != "``a`b``" {
+		t.Errorf("Expected a longer fence around a backtick, got %q", got)
+	}
+}
+
+func TestExportMarkdownTree(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644)
+	nav, _ := NewNavigator(dir)
+	nav.ScanDirectory()
+
+	var copied string
+	nav.clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	want := "- `" + filepath.Base(dir) + "/`\n  - `notes.txt`\n"
+	if lines, err := nav.ExportMarkdownTree(""); err != nil || lines != 2 || copied != want {
+		t.Errorf("Expected %q on the clipboard, got %q (%d lines, err %v)", want, copied, lines, err)
+	}
+
+	if _, err := nav.ExportMarkdownTree("tree.md"); err != nil {
+		t.Fatalf("ExportMarkdownTree failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "tree.md")); string(data) != want {
+		t.Errorf("Expected tree.md to hold %q, got %q", want, data)
+	}
+	if _, err := nav.ExportMarkdownTree("tree.md"); err == nil {
+		t.Error("Expected an existing file to be kept")
+	}
+}
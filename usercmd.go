@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+// Config keys starting with these prefixes define user commands, such as
+// "command.gitlog = git log -- {path}", and bind them to keys, as in
+// "bind.G = gitlog".
+const (
+	commandPrefix = "command."
+	bindPrefix    = "bind."
+)
+
+// setUserCommand applies a command.NAME or bind.KEY setting, reporting false
+// when key is neither.
+func (c *Config) setUserCommand(key, value string) (bool, error) {
+	if name, ok := strings.CutPrefix(key, commandPrefix); ok {
+		if name == "" || value == "" {
+			return true, fmt.Errorf("invalid command %q: expected command.NAME = TEMPLATE", key)
+		}
+		if c.Commands == nil {
+			c.Commands = make(map[string]string)
+		}
+		c.Commands[name] = value
+		return true, nil
+	}
+	if keyName, ok := strings.CutPrefix(key, bindPrefix); ok {
+		r, size := utf8.DecodeRuneInString(keyName)
+		if size == 0 || size != len(keyName) || value == "" {
+			return true, fmt.Errorf("invalid binding %q: expected bind.KEY = NAME with a single character key", key)
+		}
+		if c.Bindings == nil {
+			c.Bindings = make(map[rune]string)
+		}
+		c.Bindings[r] = value
+		return true, nil
+	}
+	return false, nil
+}
+
+// resolveUserCommand returns the name and template of the command bound to
+// key. It reports false when key is unbound, and an error when the binding
+// names a command that is not defined.
+func resolveUserCommand(cfg Config, key rune) (name, template string, ok bool, err error) {
+	name, ok = cfg.Bindings[key]
+	if !ok {
+		return "", "", false, nil
+	}
+	template, defined := cfg.Commands[name]
+	if !defined {
+		return name, "", true, fmt.Errorf("%c is bound to %q, but no command.%s is defined", key, name, name)
+	}
+	return name, template, true, nil
+}
+
+// expandCommand replaces {path}, {name} and {dir} in template with the
+// selected path, its name and the current directory, each quoted with quote
+// so names with spaces or quotes reach the command as one argument.
+func expandCommand(template, path, dir string, quote func(string) string) string {
+	return strings.NewReplacer(
+		"{path}", quote(path),
+		"{name}", quote(filepath.Base(path)),
+		"{dir}", quote(dir),
+	).Replace(template)
+}
+
+// shellQuote quotes s for the shell shellCommand runs on goos: in single
+// quotes for sh, or in double quotes for cmd, which has no way to escape them.
+func shellQuote(s, goos string) string {
+	if goos == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, "") + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellCommand returns a command running line through the platform's shell.
+func shellCommand(line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", line)
+	}
+	return exec.Command("sh", "-c", line)
+}
+
+// RunUserCommand runs the command bound to key in the current directory,
+// with the screen handed over until it exits, then rescans in case it
+// changed anything. It reports false when key is not bound.
+func (n *Navigator) RunUserCommand(key rune) (bool, error) {
+	name, template, ok, err := resolveUserCommand(n.config, key)
+	if !ok || err != nil {
+		return ok, err
+	}
+	if n.execDisabled() {
+		return true, nil
+	}
+
+	// Without a selection, as in an empty directory, the directory stands in
+	path := n.currentPath
+	if selectedItem := n.GetSelectedItem(); selectedItem != nil {
+		path = selectedItem.Path
+	}
+	quote := func(s string) string { return shellQuote(s, runtime.GOOS) }
+	cmd := shellCommand(expandCommand(template, path, n.currentPath, quote))
+	cmd.Dir = n.currentPath
+	if err := n.runForeground(cmd); err != nil {
+		return true, fmt.Errorf("%s: %w", name, err)
+	}
+	n.SetStatusMessage("Ran " + name)
+	return true, n.Refresh()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandCommand(t *testing.T) {
+	dir := filepath.FromSlash("/home/me/my project")
+	path := filepath.Join(dir, "it's.txt")
+	quote := func(s string) string { return shellQuote(s, "linux") }
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"git log -- {path}", `git log -- '/home/me/my project/it'\''s.txt'`},
+		{"echo {name} in {dir}", `echo 'it'\''s.txt' in '/home/me/my project'`},
+		{"cp {path} {path}.bak", `cp '/home/me/my project/it'\''s.txt' '/home/me/my project/it'\''s.txt'.bak`},
+		{"make", "make"},
+	}
+	for _, tt := range tests {
+		got := expandCommand(tt.template, path, dir, quote)
+		want := strings.ReplaceAll(tt.want, "/", string(filepath.Separator))
+		if got != want {
+			t.Errorf("expandCommand(%q) = %q, want %q", tt.template, got, want)
+		}
+	}
+
+	if got := shellQuote(`C:\My "Docs"`, "windows"); got != `"C:\My Docs"` {
+		t.Errorf("Expected cmd quoting to drop the inner quotes, got %q", got)
+	}
+}
+
+func TestResolveUserCommand(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+command.gitlog = git log -- {path}
+bind.G = gitlog
+bind.X = missing
+`))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+
+	name, template, ok, err := resolveUserCommand(cfg, 'G')
+	if !ok || err != nil || name != "gitlog" || template != "git log -- {path}" {
+		t.Errorf("Expected G to run gitlog, got %q %q (ok %v, err %v)", name, template, ok, err)
+	}
+	if _, _, ok, err := resolveUserCommand(cfg, 'X'); !ok || err == nil {
+		t.Errorf("Expected an error for a binding to an undefined command, got ok %v, err %v", ok, err)
+	}
+	if _, _, ok, _ := resolveUserCommand(cfg, 'Z'); ok {
+		t.Error("Expected Z to be unbound")
+	}
+
+	for _, line := range []string{"bind.GG = gitlog", "bind. = gitlog", "command. = ls", "command.empty ="} {
+		if _, err := parseConfig(strings.NewReader(line)); err == nil {
+			t.Errorf("Expected %q to be rejected", line)
+		}
+	}
+}
+
+func TestRunUserCommand(t *testing.T) {
+	tempDir, cleanup := createTestDir(t)
+	defer cleanup()
+
+	nav, _ := NewNavigator(tempDir)
+	nav.ScanDirectory()
+	cfg := nav.GetConfig()
+	cfg.set("command.show", "cat {path}")
+	cfg.set("bind.G", "show")
+	nav.SetConfig(cfg)
+
+	var ran *exec.Cmd
+	nav.SetForegroundRunner(func(cmd *exec.Cmd) error {
+		ran = cmd
+		return nil
+	})
+
+	selectByName(t, nav, "file1.txt")
+	if handled, err := nav.RunUserCommand('G'); !handled || err != nil {
+		t.Fatalf("Expected G to run, got handled %v, err %v", handled, err)
+	}
+	if ran == nil || ran.Dir != tempDir {
+		t.Fatalf("Expected the command to run in %q, got %v", tempDir, ran)
+	}
+	if line := ran.Args[len(ran.Args)-1]; !strings.Contains(line, "file1.txt") || !strings.HasPrefix(line, "cat ") {
+		t.Errorf("Expected the selected path substituted, got %q", line)
+	}
+
+	ran = nil
+	if handled, _ := nav.RunUserCommand('Z'); handled || ran != nil {
+		t.Error("Expected an unbound key to run nothing")
+	}
+
+	// --no-exec blocks user commands too
+	cfg.NoExec = true
+	nav.SetConfig(cfg)
+	if handled, _ := nav.RunUserCommand('G'); !handled || ran != nil {
+		t.Error("Expected --no-exec to stop the command")
+	}
+}
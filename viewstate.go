@@ -0,0 +1,41 @@
+package main
+
+// maxViewStates bounds how many directories' views are remembered.
+const maxViewStates = 4096
+
+// viewState is how a directory was last left: the selected entry and the
+// index of the first visible row.
+type viewState struct {
+	selected string
+	scroll   int
+}
+
+// saveViewState remembers the current directory's selection and scroll
+// offset when remember_view is set. The flat view is not remembered.
+func (n *Navigator) saveViewState() {
+	if !n.config.RememberView || n.flatView {
+		return
+	}
+	selectedItem := n.GetSelectedItem()
+	if selectedItem == nil {
+		return
+	}
+	if n.viewStates == nil || len(n.viewStates) >= maxViewStates {
+		n.viewStates = make(map[string]viewState)
+	}
+	n.viewStates[n.currentPath] = viewState{selected: selectedItem.Name, scroll: n.scrollOffset}
+}
+
+// restoreViewState reapplies the remembered view of the current directory.
+// The scroll offset is corrected on the next draw, should the listing have
+// changed since.
+func (n *Navigator) restoreViewState() {
+	if !n.config.RememberView {
+		return
+	}
+	state, ok := n.viewStates[n.currentPath]
+	if !ok || !n.selectName(state.selected) {
+		return
+	}
+	n.scrollOffset = state.scroll
+}
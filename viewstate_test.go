@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRememberView(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		os.WriteFile(filepath.Join(tempDir, fmt.Sprintf("file%02d.txt", i)), nil, 0644)
+	}
+	os.Mkdir(filepath.Join(tempDir, "sub"), 0755)
+
+	nav, _ := NewNavigator(tempDir)
+	cfg := nav.GetConfig()
+	cfg.RememberView = true
+	nav.SetConfig(cfg)
+	nav.ScanDirectory()
+
+	selectByName(t, nav, "file30.txt")
+	nav.EnsureVisible(10)
+	wantOffset := nav.GetScrollOffset()
+	if wantOffset == 0 {
+		t.Fatal("Expected the listing to scroll")
+	}
+
+	nav.GoToPath(filepath.Join(tempDir, "sub") + "/")
+	if nav.GetScrollOffset() != 0 {
+		t.Errorf("Expected a new directory to start at the top, got offset %d", nav.GetScrollOffset())
+	}
+	nav.GoToPath(tempDir + "/")
+	if selected := nav.GetSelectedItem(); selected == nil || selected.Name != "file30.txt" {
+		t.Errorf("Expected file30.txt selected again, got %v", selected)
+	}
+	if nav.GetScrollOffset() != wantOffset {
+		t.Errorf("Expected scroll offset %d restored, got %d", wantOffset, nav.GetScrollOffset())
+	}
+
+	// Without the option directories open at the top
+	cfg.RememberView = false
+	nav.SetConfig(cfg)
+	nav.GoToPath(filepath.Join(tempDir, "sub") + "/")
+	nav.GoToPath(tempDir + "/")
+	if nav.GetSelectedIndex() != 0 || nav.GetScrollOffset() != 0 {
+		t.Errorf("Expected the top without remember_view, got index %d offset %d", nav.GetSelectedIndex(), nav.GetScrollOffset())
+	}
+}
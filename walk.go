@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// defaultMaxDepth is how many levels recursive operations descend by default.
+const defaultMaxDepth = 256
+
+// DepthError reports a directory nested deeper than the max_depth setting.
+type DepthError struct {
+	Path  string
+	Limit int
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("%s: nested deeper than %d levels (max_depth)", e.Path, e.Limit)
+}
+
+// errStopWalk ends a walk early without reporting an error.
+var errStopWalk = errors.New("stop walk")
+
+// walkEntry is an entry met by walkTree.
+type walkEntry struct {
+	FileItem
+	Depth int  // 1 for the entries of the root
+	Last  bool // No sibling follows it
+}
+
+// walkTree visits everything below root depth-first, each directory's entries
+// directories first and every directory before its contents. Symlinks are
+// visited but never followed.
+//
+// visit returns filepath.SkipDir to leave out a directory's contents,
+// errStopWalk to end the walk quietly, or another error to stop with it. When
+// a directory cannot be read, visit is called for it again with the error,
+// and returning nil carries on. Descending more than maxDepth levels below
+// root fails with a *DepthError, so every recursive operation is bounded the
+// same way; 0 means no limit.
+func walkTree(source FileSource, root string, maxDepth int, visit func(entry walkEntry, err error) error) error {
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		if maxDepth > 0 && depth > maxDepth {
+			return &DepthError{Path: dir, Limit: maxDepth}
+		}
+		entries, err := source.ReadDir(dir)
+		if err != nil {
+			dirItem := FileItem{Name: filepath.Base(dir), Path: dir, IsDir: true, Type: TypeDir}
+			return visit(walkEntry{FileItem: dirItem, Depth: depth - 1}, err)
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].IsDir && !entries[j].IsDir
+		})
+		for i, entry := range entries {
+			err := visit(walkEntry{FileItem: entry, Depth: depth, Last: i == len(entries)-1}, nil)
+			if err == filepath.SkipDir {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if entry.IsDir {
+				if err := walk(entry.Path, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	err := walk(root, 1)
+	if err == errStopWalk {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeDeepTree creates levels nested directories below dir, with a file at
+// the bottom, and returns the top one.
+func makeDeepTree(t *testing.T, dir string, levels int) string {
+	t.Helper()
+	top := filepath.Join(dir, "deep")
+	bottom := top
+	for i := 1; i < levels; i++ {
+		bottom = filepath.Join(bottom, "d")
+	}
+	if err := os.MkdirAll(bottom, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bottom, "leaf.txt"), []byte("leaf"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return top
+}
+
+func TestWalkTreeDepth(t *testing.T) {
+	top := makeDeepTree(t, t.TempDir(), 5)
+
+	// deep/d/d/d/d/leaf.txt is 5 levels below deep
+	visit := func(walkEntry, error) error { return nil }
+	if err := walkTree(osSource{}, top, 5, visit); err != nil {
+		t.Errorf("Expected a limit of 5 to allow the tree, got %v", err)
+	}
+	err := walkTree(osSource{}, top, 4, visit)
+	var depthErr *DepthError
+	if !errors.As(err, &depthErr) || depthErr.Limit != 4 {
+		t.Fatalf("Expected a DepthError for a limit of 4, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "deeper than 4 levels") {
+		t.Errorf("Expected the error to name the limit, got %q", err.Error())
+	}
+	if err := walkTree(osSource{}, top, 0, visit); err != nil {
+		t.Errorf("Expected no limit with 0, got %v", err)
+	}
+}
+
+func TestRecursiveOperationsDepthLimit(t *testing.T) {
+	dir := t.TempDir()
+	top := makeDeepTree(t, dir, 5)
+	var depthErr *DepthError
+
+	if err := copyPath(top, filepath.Join(dir, "copy"), 3, true, false); !errors.As(err, &depthErr) {
+		t.Errorf("Expected copyPath to fail with a DepthError, got %v", err)
+	}
+	if _, err := dirSize(osSource{}, top, false, 3); !errors.As(err, &depthErr) {
+		t.Errorf("Expected dirSize to fail with a DepthError, got %v", err)
+	}
+	if err := chmodPath(top, 0755, true, 3); !errors.As(err, &depthErr) {
+		t.Errorf("Expected chmodPath to fail with a DepthError, got %v", err)
+	}
+}